@@ -1,9 +1,12 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/stevekuznetsov/exec-assert/pkg/api"
@@ -31,38 +34,104 @@ var (
 	// delimiter is the delimiter to use when parsing the list of output tests
 	delimiter string
 
+	// match is how the output tests are matched, either for every test or as a comma-delimited list for each test
+	match string
+
+	// jsonTests is a delimited list of queries that must hold on the output of the bash command to stdout parsed as JSON
+	jsonTests string
+
+	// goldenFiles is a comma-delimited list of golden files that the output of the bash command must match exactly
+	goldenFiles string
+
+	// updateGoldenFiles determines if golden files should be rewritten with the output of the bash command
+	updateGoldenFiles bool
+
 	// timeout is the timeout used for the repetitive execution strategy
 	timeout time.Duration
 
 	// interval is the interval used between executions for the repetitive execution strategy
 	interval time.Duration
 
+	// multiplier is the factor by which the interval grows after each execution when backing off
+	multiplier float64
+
+	// maxInterval is the longest interval between executions when backing off
+	maxInterval time.Duration
+
+	// jitter is the fraction of each interval by which it is randomly changed when backing off
+	jitter float64
+
+	// consecutive is how many back-to-back executions must meet the assertions for the repetitive execution strategies
+	consecutive int
+
+	// attemptTimeout is the deadline for any one execution of the bash command
+	attemptTimeout time.Duration
+
+	// gracePeriod is how long a command that ran past its deadline has to exit after SIGTERM before it is sent SIGKILL
+	gracePeriod time.Duration
+
 	// name is an optional name to the test being run
 	name string
 
 	// verbose determines if the output of the command should be shown regardless of assertion failure
 	verbose bool
+
+	// contextLines is how many lines of output to show around each match instead of the whole output
+	contextLines int
+
+	// format is the format in which the test is reported
+	format string
+
+	// junitPath is the path to a JUnit XML report to record the test in
+	junitPath string
+
+	// color determines when the text report is rendered in color
+	color string
 )
 
 const (
 	defaultExecutionStrategy = "once"
 	defaultResultAssertion   = "success"
 	defaultOutputAssertion   = "ambivalent"
+	defaultMatch             = "regex"
 	defaultTimeout           = 60 * time.Second
 	defaultInterval          = 200 * time.Millisecond
+	defaultMultiplier        = 2
+	defaultMaxInterval       = 5 * time.Second
+	defaultJitter            = 0
+	defaultConsecutive       = 1
+	defaultAttemptTimeout    = 0
+	defaultGracePeriod       = 5 * time.Second
 	defaultVerbose           = false
+	defaultContext           = 0
+	defaultFormat            = "text"
+	defaultColor             = "auto"
 )
 
 func init() {
-	flag.StringVar(&executionStrategy, "execute", defaultExecutionStrategy, "how to execute the command")
+	flag.StringVar(&executionStrategy, "execute", defaultExecutionStrategy, "how to execute the command: 'once', 'until' the assertions are met, until they are met with 'backoff' between executions, or 'consistently' expecting them to hold every time")
 	flag.StringVar(&resultAssertion, "result", defaultResultAssertion, "what to assert about the result of the command execution")
-	flag.StringVar(&outputAssertions, "output", defaultOutputAssertion, "a comma-delimited list of what to assert about the result of the output test")
+	flag.StringVar(&outputAssertions, "output", defaultOutputAssertion, "a comma-delimited list of what to assert about the result of the output test, each optionally prefixed with the stream to test like 'stderr:contains'; counts are asserted with 'contains>=2', 'lines=3' or 'empty', and order with 'in-order'")
 	flag.StringVar(&outputTests, "test", "", "a delimited list of regular expressions to match lines in the output with")
 	flag.StringVar(&delimiter, "delimiter", "", "the delimiter to use when parsing the list of regular expression tests")
-	flag.DurationVar(&timeout, "timeout", defaultTimeout, "timeout when executing until a condition is met")
-	flag.DurationVar(&interval, "interval", defaultInterval, "interval between executions when executing until a condition is met")
+	flag.StringVar(&match, "match", defaultMatch, "how to match the tests, as 'literal' text, a 'glob' matching a whole line or a 'regex'; a comma-delimited list sets the mode for each test")
+	flag.StringVar(&jsonTests, "json", "", "a delimited list of queries like '.items | length >= 3' that must hold on the output to stdout parsed as JSON")
+	flag.StringVar(&goldenFiles, "golden", "", "a comma-delimited list of golden files the output must match exactly, each optionally prefixed with the stream to match like 'stderr:path'; stdout is matched by default")
+	flag.BoolVar(&updateGoldenFiles, "update-golden", os.Getenv("EXEC_ASSERT_UPDATE") == "1", "rewrite golden files with the output instead of comparing them; defaults to true if EXEC_ASSERT_UPDATE=1")
+	flag.DurationVar(&timeout, "timeout", defaultTimeout, "timeout for the whole execution, after which a running command is killed; when executing once, there is no timeout unless this is set")
+	flag.DurationVar(&interval, "interval", defaultInterval, "interval between executions when executing until a condition is met, or the first interval when backing off")
+	flag.Float64Var(&multiplier, "multiplier", defaultMultiplier, "factor by which the interval grows after each execution when backing off")
+	flag.DurationVar(&maxInterval, "max-interval", defaultMaxInterval, "longest interval between executions when backing off")
+	flag.Float64Var(&jitter, "jitter", defaultJitter, "fraction between 0 and 1 of each interval by which it is randomly lengthened or shortened when backing off")
+	flag.IntVar(&consecutive, "consecutive", defaultConsecutive, "how many executions in a row must meet the assertions when executing until they are met, or with backoff")
+	flag.DurationVar(&attemptTimeout, "attempt-timeout", defaultAttemptTimeout, "timeout for any one execution of the command, or 0 for none")
+	flag.DurationVar(&gracePeriod, "grace-period", defaultGracePeriod, "how long a command that timed out has to exit after SIGTERM before it is sent SIGKILL")
 	flag.StringVar(&name, "name", "", "an optional name for the test being run")
 	flag.BoolVar(&verbose, "v", defaultVerbose, "use verbose output")
+	flag.IntVar(&contextLines, "context", defaultContext, "show only this many lines of output around each match of an output test instead of the whole output, or 0 to show the whole output")
+	flag.StringVar(&junitPath, "junit", "", "the path to a JUnit XML report to record the test in, named by '--name'; the test is added to any report already at the path")
+	flag.StringVar(&color, "color", defaultColor, "when to render the text report in color: 'always', 'never', or 'auto' to use color when writing to a terminal and NO_COLOR is not set")
+	flag.StringVar(&format, "format", defaultFormat, "how to report the test, as 'text', as one line of 'json' holding the config, results and output, or as a 'tap' test line")
 }
 
 const (
@@ -73,13 +142,23 @@ be made about the result of the command, the output to stdout and the output to
 all assertions made about the execution of the given bash command succeed. This tool can execute the command just
 once and inspect its result and output, or it can execute the command until the result and/out output assertions
 are met. When executing until a set of assertions are met, both a timeout and interval between executions are set.
+With '--execute backoff', the interval instead starts at '--interval' and grows by '--multiplier' after each
+execution up to '--max-interval', optionally changed randomly by a fraction '--jitter' of it. With '--execute
+consistently', the command is executed every interval until the timeout passes, and the test fails at the first
+execution that breaks the assertions.
+A command still running when the timeout passes, or when its own attempt timeout passes, is killed along with its
+children and reported as having timed out. A command executed once is only bound by '--timeout' if it is set.
 Output to stdout and stderr from the command is captured but only shown if assertions fail. Set '-v' to use verbose
-output and always display output. Any regular expressions passed in as tests must not allow the shell to interpret
+output and always display output. Text that output tests matched is highlighted, and '--context' shows only some
+lines around each match instead of the whole output. When writing to a terminal, the report is rendered in color
+unless NO_COLOR is set; set '--color always' or '--color never' to choose. Set '--format json' to report the test as one line of JSON instead, following a
+versioned schema that always includes the output. Any regular expressions passed in as tests must not allow the shell to interpret
 back-slashes within them as escape characters.
 `
 
 	execAssertUsage = `Usage:
   %[1]s [OPTIONS] COMMAND
+  %[1]s suite [OPTIONS] FILE...
 `
 
 	execAssertExamples = `Examples:
@@ -89,12 +168,42 @@ back-slashes within them as escape characters.
   // Run a command and expect it to fail, with no tests on the command output
   $ %[1]s --result failure 'grep'
 
+  // Run a command and expect it to exit with code 1 or 2
+  $ %[1]s --result exit-code=1,2 'grep pattern missing-file'
+
+  // Run a command and expect it to exit with a code other than 0
+  $ %[1]s --result 'exit-code!=0' 'grep'
+
+  // Run a command and expect it to be killed by SIGSEGV
+  $ %[1]s --result signaled=SIGSEGV './crasher'
+
   // Run a command and expect it to fail, testing that the command output contains a phrase
   $ %[1]s --result failure --output contains --test "Try 'grep --help' for more information." 'grep'
 
   // Run a command and expect it to succeed, testing that the command output does not contain a regular expression
   $ %[1]s --output excludes --test '/(var|lib|bin)/' 'pwd'
 
+  // Run a command and expect it to succeed, testing that the command output contains text without escaping it
+  $ %[1]s --match literal --output contains --test '[ERROR] (x.go:12)' './build'
+
+  // Run a command and expect it to succeed, testing that a line of the command output matches a glob
+  $ %[1]s --match glob --output contains --test 'Listening on *:8080' './server --dry-run'
+
+  // Run a command and expect it to fail, testing that the error message goes to stderr and not stdout
+  $ %[1]s --result failure --output 'stderr:contains,stdout:excludes' --test 'Usage#Usage' --delimiter '#' 'grep'
+
+  // Run a command and expect its output to stdout and stderr to match golden files exactly
+  $ %[1]s --golden 'testdata/help.stdout,stderr:testdata/help.stderr' 'mycli --help'
+
+  // Run a command and rewrite the golden file with its output
+  $ %[1]s --golden testdata/help.stdout --update-golden 'mycli --help'
+
+  // Run a command and expect its output to be JSON with a specific value at a path
+  $ %[1]s --json '.items[0].status.phase == "Running"' 'kubectl get pods -o json'
+
+  // Run a command until its JSON output has at least three items
+  $ %[1]s --execute until --json '.items | length >= 3' 'kubectl get pods -o json'
+
   // Run a command until it succeeds or times out
   $ %[1]s --execute until --result success 'curl http://192.168.0.1:4000'
 
@@ -104,8 +213,29 @@ back-slashes within them as escape characters.
   // Run a command until it fails and the command output doesn't contain a regular expression
   $ %[1]s --execute until --result failure --output contains --test '(Tue|Wed)' 'date'
 
+  // Run a command until it succeeds three times in a row, so that a flapping service is not taken to be ready
+  $ %[1]s --execute until --consecutive 3 'curl -sf http://192.168.0.1:4000/healthz'
+
+  // Run a command until it succeeds, waiting 0.5s, then 1s, 2s, 4s and so on up to 30s between executions
+  $ %[1]s --execute backoff --interval 500ms --max-interval 30s --timeout 5m 'curl http://192.168.0.1:4000'
+
+  // Run a command every second for thirty seconds, expecting it to succeed every time
+  $ %[1]s --execute consistently --interval 1s --timeout 30s 'curl -sf http://192.168.0.1:4000/healthz'
+
+  // Run a command until it succeeds, killing any one execution that takes longer than five seconds
+  $ %[1]s --execute until --attempt-timeout 5s 'curl http://192.168.0.1:4000'
+
   // Run a command and name the test for more descriptive output
   $ %[1]s --name 'TestWorkingDir' 'pwd'
+
+  // Run a command and report the config, the result of every assertion and the output as JSON
+  $ %[1]s --format json --output contains --test 'ready' './server --check'
+
+  // Run a command and record it in a JUnit XML report, adding to the tests already recorded there
+  $ %[1]s --name 'TestWorkingDir' --junit junit.xml 'pwd'
+
+  // Run every test in a suite file and tally the results
+  $ %[1]s suite test/suite.json
 `
 )
 
@@ -119,6 +249,10 @@ func main() {
 		os.Exit(2)
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "suite" {
+		runSuite(os.Args[2:])
+	}
+
 	flag.Parse()
 
 	arguments := flag.Args()
@@ -129,22 +263,45 @@ func main() {
 
 	command := arguments[0]
 
+	// the default timeout bounds repeated executions; a command executed once runs for as long as it needs to
+	if executionStrategy == api.ExecutionStrategyOnce && !flagSet("timeout") {
+		timeout = 0
+	}
+
 	config := api.ExecutionAssertionConfig{
 		Command:           command,
 		ExecutionStrategy: executionStrategy,
 		ResultAssertion:   resultAssertion,
-		OutputAssertions:  outputAssertions,
-		OutputTests:       outputTests,
-		Delimiter:         delimiter,
 		Timeout:           timeout,
 		Interval:          interval,
+		Multiplier:        multiplier,
+		MaxInterval:       maxInterval,
+		Jitter:            jitter,
+		Consecutive:       consecutive,
+		AttemptTimeout:    attemptTimeout,
+		GracePeriod:       gracePeriod,
 		Name:              name,
 		Verbose:           verbose,
+		Context:           contextLines,
+	}
+
+	flags := cmd.OutputFlags{
+		OutputAssertions:  outputAssertions,
+		OutputTests:       outputTests,
+		Delimiter:         delimiter,
+		Match:             match,
+		JSONTests:         jsonTests,
+		GoldenFiles:       goldenFiles,
+		UpdateGoldenFiles: updateGoldenFiles,
 	}
 
 	options := cmd.ExecuteAssertOptions{
-		Config: config,
-		Output: os.Stdout,
+		Config:    config,
+		Flags:     flags,
+		Format:    format,
+		JUnitPath: junitPath,
+		Color:     color,
+		Output:    os.Stdout,
 	}
 
 	if err := options.Complete(); err != nil {
@@ -157,14 +314,134 @@ func main() {
 		os.Exit(1)
 	}
 
-	result, err := options.Run()
+	ctx, stop := interruptible()
+	result, err := options.Run(ctx)
+	interrupted := ctx.Err() != nil
+	stop()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error executing: %v\n", err)
 		os.Exit(1)
 	}
+	if interrupted {
+		fmt.Fprintln(os.Stderr, "Interrupted while executing.")
+		os.Exit(1)
+	}
 	if result {
 		os.Exit(0)
 	} else {
 		os.Exit(1)
 	}
 }
+
+const execAssertSuiteLong = `Run every test in suite files and tally the results.
+
+A suite file is a JSON document holding a list of tests, each of which sets the same configuration as the flags
+that run one test. Every test is run, even after one fails, and the suite fails unless every test passes:
+
+  {
+    "tests": [
+      {
+        "name": "TestWorkingDir",
+        "command": "pwd",
+        "result": "success",
+        "output": [
+          {"assertion": "contains", "target": "stdout", "test": "/"},
+          {"assertion": "in-order", "tests": ["starting", "ready"], "match": "literal"}
+        ],
+        "json": [".items | length >= 3"],
+        "golden": [{"target": "stderr", "path": "testdata/err.golden"}],
+        "execute": "until",
+        "timeout": "10s",
+        "interval": "1s"
+      }
+    ]
+  }
+`
+
+// runSuite runs every test in the suite files given as arguments and exits
+func runSuite(arguments []string) {
+	flags := flag.NewFlagSet("suite", flag.ExitOnError)
+	suiteVerbose := flags.Bool("v", defaultVerbose, "use verbose output for every test")
+	suiteContext := flags.Int("context", defaultContext, "show only this many lines of output around each match of an output test instead of the whole output, unless a test sets its own context")
+	suiteFormat := flags.String("format", defaultFormat, "how to report every test and the tally, as 'text', as 'json' with one line per test and a last line for the tally, or as a 'tap' stream with a plan")
+	suiteColor := flags.String("color", defaultColor, "when to render the text reports and the tally in color: 'always', 'never', or 'auto' to use color when writing to a terminal and NO_COLOR is not set")
+	suiteJUnitPath := flags.String("junit", "", "the path to a JUnit XML report to record every test in; tests are added to any report already at the path")
+	suiteUpdateGoldenFiles := flags.Bool("update-golden", os.Getenv("EXEC_ASSERT_UPDATE") == "1", "rewrite golden files with the output instead of comparing them; defaults to true if EXEC_ASSERT_UPDATE=1")
+	flags.Usage = func() {
+		fmt.Fprint(os.Stderr, execAssertSuiteLong+"\n")
+		fmt.Fprintf(os.Stderr, "Usage:\n  %s suite [OPTIONS] FILE...\n\n", os.Args[0])
+		fmt.Fprintln(os.Stderr, "Options:")
+		flags.PrintDefaults()
+		os.Exit(2)
+	}
+	flags.Parse(arguments)
+
+	if flags.NArg() == 0 {
+		fmt.Fprintf(os.Stderr, "%s suite expects at least one suite file.\n", os.Args[0])
+		os.Exit(1)
+	}
+
+	options := cmd.SuiteOptions{
+		Paths: flags.Args(),
+		Defaults: api.ExecutionAssertionConfig{
+			ExecutionStrategy: defaultExecutionStrategy,
+			ResultAssertion:   defaultResultAssertion,
+			Timeout:           defaultTimeout,
+			Interval:          defaultInterval,
+			Multiplier:        defaultMultiplier,
+			MaxInterval:       defaultMaxInterval,
+			Jitter:            defaultJitter,
+			Consecutive:       defaultConsecutive,
+			AttemptTimeout:    defaultAttemptTimeout,
+			GracePeriod:       defaultGracePeriod,
+			Verbose:           *suiteVerbose,
+			Context:           *suiteContext,
+		},
+		UpdateGoldenFiles: *suiteUpdateGoldenFiles,
+		Format:            *suiteFormat,
+		JUnitPath:         *suiteJUnitPath,
+		Color:             *suiteColor,
+		Output:            os.Stdout,
+	}
+
+	if err := options.Complete(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error configuring suite: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := options.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error validating suite: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := interruptible()
+	result, err := options.Run(ctx)
+	stop()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing suite: %v\n", err)
+		os.Exit(1)
+	}
+	if result {
+		os.Exit(0)
+	} else {
+		os.Exit(1)
+	}
+}
+
+// interruptible returns a context that is done when exec-assert is sent SIGINT or SIGTERM. The command runs in its own
+// process group, so it does not receive the signals sent to exec-assert; instead, it and every process it started are
+// killed when the context is done, so that none of them outlive exec-assert.
+func interruptible() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
+
+// flagSet determines if the flag with the given name was set on the command line
+func flagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
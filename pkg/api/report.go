@@ -0,0 +1,267 @@
+package api
+
+// ReportFormat determines how the configuration and results of tests are reported
+type ReportFormat string
+
+const (
+	ReportFormatText = "text"
+	ReportFormatJSON = "json"
+	ReportFormatTAP  = "tap"
+)
+
+var ValidReportFormats = []ReportFormat{ReportFormatText, ReportFormatJSON, ReportFormatTAP}
+
+// ColorMode determines when text reports are rendered in color
+type ColorMode string
+
+const (
+	ColorModeAuto   = "auto"
+	ColorModeAlways = "always"
+	ColorModeNever  = "never"
+)
+
+var ValidColorModes = []ColorMode{ColorModeAuto, ColorModeAlways, ColorModeNever}
+
+// ReportSchemaVersion is the version of the schema of machine-readable reports. Fields may be added to
+// a version of the schema, but they are never removed nor do they change meaning without a new version.
+const ReportSchemaVersion = "exec-assert/v1"
+
+// ReportKind identifies what a machine-readable report is about
+type ReportKind string
+
+const (
+	ReportKindTest  = "test"
+	ReportKindSuite = "suite"
+)
+
+// Report is the machine-readable report of one test. Durations and offsets are in seconds.
+type Report struct {
+	// SchemaVersion is the version of the schema of this report
+	SchemaVersion string `json:"schemaVersion"`
+
+	// Kind is always ReportKindTest
+	Kind ReportKind `json:"kind"`
+
+	// Name is the name of the test, if it has one
+	Name string `json:"name,omitempty"`
+
+	// Passed determines if every assertion about the test was met
+	Passed bool `json:"passed"`
+
+	// Error is why the test could not be run, if it could not; no other results are reported then
+	Error string `json:"error,omitempty"`
+
+	// Config is how the test was configured
+	Config *ReportConfig `json:"config,omitempty"`
+
+	// Duration is how long it took the command to execute, over every attempt
+	Duration float64 `json:"duration"`
+
+	// Attempts is how many times the command was executed
+	Attempts int `json:"attempts"`
+
+	// ExitCode is the code that the last execution of the command exited with, if it exited on its own
+	ExitCode *int `json:"exitCode"`
+
+	// Signal is the name of the signal that killed the last execution of the command, if one did
+	Signal string `json:"signal,omitempty"`
+
+	// TimedOut determines if the last execution of the command was killed for running past its deadline
+	TimedOut bool `json:"timedOut"`
+
+	// Result is the result of the assertion about the result of the command
+	Result ReportResult `json:"result"`
+
+	// Assertions are the results of each assertion about the output of the command
+	Assertions []ReportAssertion `json:"assertions"`
+
+	// Consecutive is the result of the assertion that enough executions in a row met the other assertions,
+	// if more than one execution in a row was required
+	Consecutive *ReportConsecutive `json:"consecutive,omitempty"`
+
+	// Stdout is the output of the last execution of the command to stdout
+	Stdout string `json:"stdout"`
+
+	// Stderr is the output of the last execution of the command to stderr
+	Stderr string `json:"stderr"`
+
+	// Combined are the lines of output of the last execution of the command to stdout and stderr, in order
+	Combined []ReportLine `json:"combined"`
+
+	// Timeline records every execution of the command, in order, when it is executed until assertions are met
+	Timeline []ReportAttempt `json:"timeline,omitempty"`
+}
+
+// ReportAttempt is the record of one execution of the command
+type ReportAttempt struct {
+	// Start is how long after the first execution started that this execution started
+	Start float64 `json:"start"`
+
+	// Duration is how long this execution took
+	Duration float64 `json:"duration"`
+
+	// ExitCode is the code that this execution exited with, if it exited on its own
+	ExitCode *int `json:"exitCode"`
+
+	// Signal is the name of the signal that killed this execution, if one did
+	Signal string `json:"signal,omitempty"`
+
+	// TimedOut determines if this execution was killed for running past its deadline
+	TimedOut bool `json:"timedOut"`
+
+	// Result determines if the assertion about the result was met by this execution
+	Result bool `json:"result"`
+
+	// Assertions determine if each assertion about the output was met by this execution, in the order of the assertions
+	Assertions []bool `json:"assertions"`
+}
+
+// ReportConfig is how a test was configured
+type ReportConfig struct {
+	// Command is the command that was executed
+	Command string `json:"command"`
+
+	// Execute is the execution strategy
+	Execute string `json:"execute"`
+
+	// Timeout is the timeout for the whole execution
+	Timeout float64 `json:"timeout"`
+
+	// Interval is the interval between executions
+	Interval float64 `json:"interval"`
+
+	// AttemptTimeout is the timeout for any one execution, or 0 for none
+	AttemptTimeout float64 `json:"attemptTimeout"`
+
+	// GracePeriod is how long a command that timed out had to exit after SIGTERM
+	GracePeriod float64 `json:"gracePeriod"`
+
+	// Consecutive is how many back-to-back executions had to meet the assertions, if more than one
+	Consecutive int `json:"consecutive,omitempty"`
+
+	// Multiplier is the factor by which the interval grew after each execution, when backing off
+	Multiplier float64 `json:"multiplier,omitempty"`
+
+	// MaxInterval is the longest interval between executions, when backing off
+	MaxInterval float64 `json:"maxInterval,omitempty"`
+
+	// Jitter is the fraction of each interval by which it was randomly changed, when backing off
+	Jitter float64 `json:"jitter,omitempty"`
+}
+
+// ReportResult is the result of the assertion about the result of the command
+type ReportResult struct {
+	// Assertion is the assertion, as it was configured
+	Assertion string `json:"assertion"`
+
+	// Passed determines if the assertion was met
+	Passed bool `json:"passed"`
+
+	// Reason describes why the assertion was or was not met
+	Reason string `json:"reason"`
+}
+
+// ReportConsecutive is the result of the assertion that enough executions in a row met the other assertions
+type ReportConsecutive struct {
+	// Required is how many executions in a row had to meet the assertions
+	Required int `json:"required"`
+
+	// Longest is the most executions in a row that met the assertions
+	Longest int `json:"longest"`
+
+	// Passed determines if the assertion was met
+	Passed bool `json:"passed"`
+
+	// Reason describes why the assertion was or was not met
+	Reason string `json:"reason"`
+}
+
+// ReportAssertion is the result of one assertion about the output of the command
+type ReportAssertion struct {
+	// Kind is the kind of assertion
+	Kind OutputAssertion `json:"kind"`
+
+	// Target is the stream that was tested
+	Target OutputTarget `json:"target,omitempty"`
+
+	// Pattern is the test, query or golden file path of the assertion
+	Pattern string `json:"pattern,omitempty"`
+
+	// Match is how the pattern was matched
+	Match MatchMode `json:"match,omitempty"`
+
+	// Count is the count that was asserted, for counting assertions
+	Count *ReportCount `json:"count,omitempty"`
+
+	// Sequence are the tests that had to match in order, for in-order assertions
+	Sequence []string `json:"sequence,omitempty"`
+
+	// Passed determines if the assertion was met
+	Passed bool `json:"passed"`
+
+	// Reason describes why the assertion was or was not met
+	Reason string `json:"reason,omitempty"`
+
+	// Spans are where the patterns of the assertion matched the output
+	Spans []ReportSpan `json:"spans,omitempty"`
+}
+
+// ReportSpan is a match of a pattern in the output
+type ReportSpan struct {
+	// Target is the stream that was matched, or the combined output
+	Target OutputTarget `json:"target"`
+
+	// Start is the offset in bytes of the start of the match in the stream
+	Start int `json:"start"`
+
+	// End is the offset in bytes of the end of the match in the stream
+	End int `json:"end"`
+
+	// Line is the line that the match starts on, counting from one
+	Line int `json:"line"`
+
+	// Text is the text that matched
+	Text string `json:"text"`
+}
+
+// ReportCount is the count asserted by a counting assertion
+type ReportCount struct {
+	// Operator is how the count was compared
+	Operator CountOperator `json:"operator"`
+
+	// Count is the count that was compared against
+	Count int `json:"count"`
+}
+
+// ReportLine is one line of output written by the command
+type ReportLine struct {
+	// Stream is the stream the line was written to
+	Stream OutputStream `json:"stream"`
+
+	// Offset is how long after the command started the line was written
+	Offset float64 `json:"offset"`
+
+	// Text is the content of the line, without the trailing newline
+	Text string `json:"text"`
+}
+
+// SuiteReport is the machine-readable report of a suite of tests, which follows the reports of its tests
+type SuiteReport struct {
+	// SchemaVersion is the version of the schema of this report
+	SchemaVersion string `json:"schemaVersion"`
+
+	// Kind is always ReportKindSuite
+	Kind ReportKind `json:"kind"`
+
+	// Passed determines if every test in the suite passed
+	Passed bool `json:"passed"`
+
+	// Tests is how many tests were run
+	Tests int `json:"tests"`
+
+	// PassedTests is how many tests passed
+	PassedTests int `json:"passedTests"`
+
+	// FailedTests are the names of the tests that failed
+	FailedTests []string `json:"failedTests"`
+}
@@ -1,6 +1,9 @@
 package api
 
-import "time"
+import (
+	"syscall"
+	"time"
+)
 
 // ExecutionAssertionConfig holds the configuration data for an execution and assertions
 type ExecutionAssertionConfig struct {
@@ -13,38 +16,60 @@ type ExecutionAssertionConfig struct {
 	// ResultAssertion is the type of result assertion to make
 	ResultAssertion string
 
-	// OutputAssertions is the type of output assertion to make
-	OutputAssertions string
-
-	// OutputTests is the regular expression to test the output with
-	OutputTests string
-
-	// Delimiter is the delimiter to use when parsing the list of OutputTests
-	Delimiter string
+	// Assertions are the assertions to make about the output
+	Assertions []Assertion
 
 	// Timeout is the timeout for repeated execution
 	Timeout time.Duration
 
-	// Interval is the interval betewen repeated executions
+	// Interval is the interval betewen repeated executions, or the first interval when backing off
 	Interval time.Duration
 
+	// Multiplier is the factor by which the interval grows after each execution when backing off
+	Multiplier float64
+
+	// MaxInterval is the longest interval between executions when backing off
+	MaxInterval time.Duration
+
+	// Jitter is the fraction of each interval by which it is randomly lengthened or shortened when
+	// backing off, so that many commands do not execute in lockstep
+	Jitter float64
+
+	// Consecutive is how many back-to-back executions must meet the assertions when executing until
+	// they are met; one execution is enough if it is not set
+	Consecutive int
+
+	// AttemptTimeout is the deadline for any one execution of the command. A zero AttemptTimeout
+	// means that only the overall Timeout bounds an execution.
+	AttemptTimeout time.Duration
+
+	// GracePeriod is how long a command is given to exit after it is sent SIGTERM for running past
+	// its deadline before it is sent SIGKILL
+	GracePeriod time.Duration
+
 	// Name is the optional name of the test being run
 	Name string
 
 	// Verbose determines if output to stdout and stderr should be shown always.
 	// The default behavior is to only show this output if assertions fail.
 	Verbose bool
+
+	// Context is how many lines of output to show around each match of an output test instead of the
+	// whole output, or zero to show the whole output
+	Context int
 }
 
 // ExecutionStrategy determines the type of executor to use
 type ExecutionStrategy string
 
 const (
-	ExecutionStrategyOnce  = "once"
-	ExecutionStrategyUntil = "until"
+	ExecutionStrategyOnce         = "once"
+	ExecutionStrategyUntil        = "until"
+	ExecutionStrategyBackoff      = "backoff"
+	ExecutionStrategyConsistently = "consistently"
 )
 
-var ValidExecutionStrategies = []ExecutionStrategy{ExecutionStrategyOnce, ExecutionStrategyUntil}
+var ValidExecutionStrategies = []ExecutionStrategy{ExecutionStrategyOnce, ExecutionStrategyUntil, ExecutionStrategyBackoff, ExecutionStrategyConsistently}
 
 // ResultAssertion determines which result tester to use
 type ResultAssertion string
@@ -53,9 +78,77 @@ const (
 	ResultAssertionSuccess    = "success"
 	ResultAssertionFailure    = "failure"
 	ResultAssertionAmbivalent = "ambivalent"
+	ResultAssertionExitCode   = "exit-code"
+	ResultAssertionSignaled   = "signaled"
 )
 
-var ValidResultAssertions = []ResultAssertion{ResultAssertionSuccess, ResultAssertionFailure, ResultAssertionAmbivalent}
+var ValidResultAssertions = []ResultAssertion{ResultAssertionSuccess, ResultAssertionFailure, ResultAssertionAmbivalent, ResultAssertionExitCode, ResultAssertionSignaled}
+
+// ResultAssertionArguments holds the arguments to those result assertions that take them
+type ResultAssertionArguments struct {
+	// ExitCodes are the exit codes expected by an exit code result assertion
+	ExitCodes ExitCodes
+
+	// Signal is the signal expected by a signaled result assertion, or zero if any signal is expected
+	Signal syscall.Signal
+}
+
+// ExitCodes is a set of exit codes that an exit code result assertion expects the command to exit with
+type ExitCodes struct {
+	// Ranges are the ranges of exit codes in the set
+	Ranges []ExitCodeRange
+
+	// Negated determines if the command is instead expected to exit with a code outside of the set
+	Negated bool
+}
+
+// ExitCodeRange is an inclusive range of exit codes
+type ExitCodeRange struct {
+	Min int
+	Max int
+}
+
+// MatchMode determines how an output test is matched against the output
+type MatchMode string
+
+const (
+	MatchModeLiteral = "literal"
+	MatchModeGlob    = "glob"
+	MatchModeRegex   = "regex"
+)
+
+var ValidMatchModes = []MatchMode{MatchModeLiteral, MatchModeGlob, MatchModeRegex}
+
+// Assertion is one assertion about the output of the command
+type Assertion struct {
+	// Kind is the kind of assertion to make
+	Kind OutputAssertion
+
+	// Target is the stream the assertion tests; both stdout and stderr are tested if it is not set
+	Target OutputTarget
+
+	// Pattern is what the output is tested with: a test for the contains, excludes and match-count
+	// assertions, a query for the JSON assertion, or a path for the golden file assertion
+	Pattern string
+
+	// Options are the options that only some kinds of assertions take
+	Options AssertionOptions
+}
+
+// AssertionOptions holds the options to those output assertions that take them
+type AssertionOptions struct {
+	// Match is how the Pattern or Sequence is matched; a regular expression is used if it is not set
+	Match MatchMode
+
+	// Count is the comparison that a match-count or line-count assertion makes
+	Count CountComparison
+
+	// Sequence are the tests that an in-order assertion expects to match in order
+	Sequence []string
+
+	// Update determines if a golden file should be rewritten with the output instead
+	Update bool
+}
 
 // OutputAssertion determines which output tester to use
 type OutputAssertion string
@@ -64,9 +157,41 @@ const (
 	OutputAssertionContains   = "contains"
 	OutputAssertionExcludes   = "excludes"
 	OutputAssertionAmbivalent = "ambivalent"
+	OutputAssertionMatchCount = "match-count"
+	OutputAssertionLineCount  = "line-count"
+	OutputAssertionInOrder    = "in-order"
+	OutputAssertionJSON       = "json"
+	OutputAssertionGolden     = "golden"
+)
+
+var ValidOutputAssertions = []OutputAssertion{OutputAssertionContains, OutputAssertionExcludes, OutputAssertionAmbivalent, OutputAssertionMatchCount, OutputAssertionLineCount, OutputAssertionInOrder, OutputAssertionJSON, OutputAssertionGolden}
+
+// CountOperator determines how a count is compared
+type CountOperator string
+
+const (
+	CountOperatorExactly = "="
+	CountOperatorAtLeast = ">="
+	CountOperatorAtMost  = "<="
 )
 
-var ValidOutputAssertions = []OutputAssertion{OutputAssertionContains, OutputAssertionExcludes, OutputAssertionAmbivalent}
+// CountComparison is a comparison that a count of matches or lines must satisfy
+type CountComparison struct {
+	Operator CountOperator
+	Count    int
+}
+
+// OutputTarget determines which stream an output tester tests
+type OutputTarget string
+
+const (
+	OutputTargetAny      = "any"
+	OutputTargetStdout   = "stdout"
+	OutputTargetStderr   = "stderr"
+	OutputTargetCombined = "combined"
+)
+
+var ValidOutputTargets = []OutputTarget{OutputTargetAny, OutputTargetStdout, OutputTargetStderr, OutputTargetCombined}
 
 // ExecutionAssertionResults holds the full output of an execution and assertions
 type ExecutionAssertionResults struct {
@@ -79,12 +204,114 @@ type ExecutionAssertionResults struct {
 	// ResultAssertion holds the result of the result assertion
 	ResultAssertion bool
 
-	// Stdout holds the output of the command to standard out during execution
+	// ResultVerdict holds the verdict of the result assertion
+	ResultVerdict Verdict
+
+	// Stdout holds the output of the last execution of the command to standard out
 	Stdout string
 
-	// Stderr holds the output of the command to standard error during execution
+	// Stderr holds the output of the last execution of the command to standard error
 	Stderr string
 
+	// Combined holds the lines of output of the last execution of the command to both standard
+	// out and standard error, in the order in which they were written
+	Combined []OutputLine
+
 	// OutputAssertion holds the result of the output assertion
 	OutputAssertion bool
+
+	// OutputVerdicts hold the verdict of each output assertion, in the order in which the assertions
+	// were configured
+	OutputVerdicts []Verdict
+
+	// ConsecutiveVerdict holds the verdict of the assertion that enough executions in a row met the
+	// result and output assertions, if more than one execution in a row was required
+	ConsecutiveVerdict *Verdict
+
+	// Attempts hold the record of every execution of the command, in order
+	Attempts []Attempt
+}
+
+// Attempt is the record of one execution of the command
+type Attempt struct {
+	// Start is how long after the first execution started that this execution started
+	Start time.Duration
+
+	// Duration is how long this execution took
+	Duration time.Duration
+
+	// Result is the result generated by os/exec from this execution
+	Result error
+
+	// Stdout holds the output of this execution to standard out
+	Stdout string
+
+	// Stderr holds the output of this execution to standard error
+	Stderr string
+
+	// RawStdout and RawStderr hold the output of this execution exactly as it was written, where
+	// Stdout and Stderr have their trailing newlines trimmed
+	RawStdout string
+	RawStderr string
+
+	// Combined holds the lines of output of this execution to both standard out and standard error,
+	// in the order in which they were written
+	Combined []OutputLine
+
+	// ResultVerdict holds the verdict of the result assertion on this execution
+	ResultVerdict Verdict
+
+	// OutputVerdicts hold the verdict of each output assertion on the output of this execution, in
+	// the order in which the assertions were configured
+	OutputVerdicts []Verdict
+}
+
+// Verdict is the verdict of one assertion about the result or output of the command
+type Verdict struct {
+	// Passed determines if the assertion was met
+	Passed bool
+
+	// Reason describes why the assertion was or was not met, like "Command output to stdout did not contain `ready`"
+	Reason string
+
+	// Spans are where the patterns of the assertion matched the output, if they did
+	Spans []Span
+}
+
+// Span is a match of a pattern in the output
+type Span struct {
+	// Target is the stream that was matched, or the combined output
+	Target OutputTarget
+
+	// Start is the offset in bytes of the start of the match in the stream
+	Start int
+
+	// End is the offset in bytes of the end of the match in the stream
+	End int
+
+	// Line is the line that the match starts on, counting from one
+	Line int
+
+	// Text is the text that matched
+	Text string
+}
+
+// OutputStream identifies a stream the command writes output to
+type OutputStream string
+
+const (
+	OutputStreamStdout = "stdout"
+	OutputStreamStderr = "stderr"
+)
+
+// OutputLine is one line of output written by the command
+type OutputLine struct {
+	// Stream is the stream the line was written to
+	Stream OutputStream
+
+	// Offset is how long after the command started the line was written
+	Offset time.Duration
+
+	// Text is the content of the line, without the trailing newline
+	Text string
 }
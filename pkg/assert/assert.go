@@ -0,0 +1,204 @@
+package assert
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+	"github.com/stevekuznetsov/exec-assert/pkg/cmd"
+	"github.com/stevekuznetsov/exec-assert/pkg/util"
+)
+
+const (
+	defaultTimeout     = 60 * time.Second
+	defaultInterval    = 200 * time.Millisecond
+	defaultGracePeriod = 5 * time.Second
+)
+
+// Option configures a test, adding an assertion or changing how the command is executed
+type Option func(config *api.ExecutionAssertionConfig)
+
+// Success asserts that the command succeeds; this is the default result assertion
+func Success() Option {
+	return result(api.ResultAssertionSuccess)
+}
+
+// Failure asserts that the command fails
+func Failure() Option {
+	return result(api.ResultAssertionFailure)
+}
+
+// AnyResult makes no assertion about the result of the command
+func AnyResult() Option {
+	return result(api.ResultAssertionAmbivalent)
+}
+
+// ExitCode asserts that the command exits with one of the codes
+func ExitCode(codes ...int) Option {
+	formatted := make([]string, len(codes))
+	for i, code := range codes {
+		formatted[i] = strconv.Itoa(code)
+	}
+	return result(fmt.Sprintf("%s=%s", api.ResultAssertionExitCode, strings.Join(formatted, ",")))
+}
+
+// Signaled asserts that the command is killed by the signal, or by any signal if it is zero
+func Signaled(signal syscall.Signal) Option {
+	if signal == 0 {
+		return result(fmt.Sprintf("%s=any", api.ResultAssertionSignaled))
+	}
+	return result(fmt.Sprintf("%s=%s", api.ResultAssertionSignaled, util.SignalName(signal)))
+}
+
+// result sets the result assertion
+func result(resultAssertion string) Option {
+	return func(config *api.ExecutionAssertionConfig) {
+		config.ResultAssertion = resultAssertion
+	}
+}
+
+// StdoutContains asserts that the output to stdout matches the regular expression
+func StdoutContains(pattern *regexp.Regexp) Option {
+	return matches(api.OutputAssertionContains, api.OutputTargetStdout, pattern)
+}
+
+// StdoutExcludes asserts that the output to stdout does not match the regular expression
+func StdoutExcludes(pattern *regexp.Regexp) Option {
+	return matches(api.OutputAssertionExcludes, api.OutputTargetStdout, pattern)
+}
+
+// StderrContains asserts that the output to stderr matches the regular expression
+func StderrContains(pattern *regexp.Regexp) Option {
+	return matches(api.OutputAssertionContains, api.OutputTargetStderr, pattern)
+}
+
+// StderrExcludes asserts that the output to stderr does not match the regular expression
+func StderrExcludes(pattern *regexp.Regexp) Option {
+	return matches(api.OutputAssertionExcludes, api.OutputTargetStderr, pattern)
+}
+
+// OutputContains asserts that the output to either stdout or stderr matches the regular expression
+func OutputContains(pattern *regexp.Regexp) Option {
+	return matches(api.OutputAssertionContains, api.OutputTargetAny, pattern)
+}
+
+// OutputExcludes asserts that the output to neither stdout nor stderr matches the regular expression
+func OutputExcludes(pattern *regexp.Regexp) Option {
+	return matches(api.OutputAssertionExcludes, api.OutputTargetAny, pattern)
+}
+
+// matches makes an assertion about the output with a regular expression
+func matches(kind api.OutputAssertion, target api.OutputTarget, pattern *regexp.Regexp) Option {
+	return Assert(api.Assertion{Kind: kind, Target: target, Pattern: pattern.String(), Options: api.AssertionOptions{Match: api.MatchModeRegex}})
+}
+
+// Assert makes any assertion about the output, like a count, an order, a JSON query or a golden file
+func Assert(assertion api.Assertion) Option {
+	return func(config *api.ExecutionAssertionConfig) {
+		config.Assertions = append(config.Assertions, assertion)
+	}
+}
+
+// Timeout sets the timeout for the whole execution, after which a running command is killed
+func Timeout(timeout time.Duration) Option {
+	return func(config *api.ExecutionAssertionConfig) {
+		config.Timeout = timeout
+	}
+}
+
+// Interval sets the interval between executions when executing until assertions are met
+func Interval(interval time.Duration) Option {
+	return func(config *api.ExecutionAssertionConfig) {
+		config.Interval = interval
+	}
+}
+
+// AttemptTimeout sets the timeout for any one execution of the command
+func AttemptTimeout(attemptTimeout time.Duration) Option {
+	return func(config *api.ExecutionAssertionConfig) {
+		config.AttemptTimeout = attemptTimeout
+	}
+}
+
+// Verbose shows the output of the command even if the assertions are met
+func Verbose() Option {
+	return func(config *api.ExecutionAssertionConfig) {
+		config.Verbose = true
+	}
+}
+
+// Once executes the command once and asserts something about its result and output, reporting failures
+// to the test. Unless another result assertion is made, the command is expected to succeed.
+func Once(t testing.TB, command string, options ...Option) bool {
+	t.Helper()
+	return run(t, api.ExecutionStrategyOnce, command, options)
+}
+
+// Eventually executes the command until its result and output meet the assertions or the timeout passes,
+// reporting failures to the test. Unless another result assertion is made, the command is expected to succeed.
+func Eventually(t testing.TB, command string, options ...Option) bool {
+	t.Helper()
+	return run(t, api.ExecutionStrategyUntil, command, options)
+}
+
+// run runs the test the same way that a test configured with flags is run, reporting the summary to the test
+func run(t testing.TB, executionStrategy, command string, options []Option) bool {
+	t.Helper()
+
+	// a command executed once runs for as long as it needs to, unless a timeout is set
+	timeout := defaultTimeout
+	if executionStrategy == api.ExecutionStrategyOnce {
+		timeout = 0
+	}
+
+	config := api.ExecutionAssertionConfig{
+		Command:           command,
+		ExecutionStrategy: executionStrategy,
+		ResultAssertion:   api.ResultAssertionSuccess,
+		Timeout:           timeout,
+		Interval:          defaultInterval,
+		GracePeriod:       defaultGracePeriod,
+		Name:              t.Name(),
+	}
+	for _, option := range options {
+		option(&config)
+	}
+
+	var summary bytes.Buffer
+	assertOptions := cmd.ExecuteAssertOptions{
+		Config:       config,
+		Output:       &summary,
+		Declarations: ioutil.Discard,
+	}
+
+	if err := assertOptions.Complete(); err != nil {
+		t.Errorf("Error configuring test: %v", err)
+		return false
+	}
+
+	if err := assertOptions.Validate(); err != nil {
+		t.Errorf("Error validating configuration: %v", err)
+		return false
+	}
+
+	success, err := assertOptions.Run(context.Background())
+	if err != nil {
+		t.Errorf("Error executing: %v", err)
+		return false
+	}
+
+	if success {
+		t.Log(strings.TrimRight(summary.String(), "\n"))
+	} else {
+		t.Errorf("%s", strings.TrimRight(summary.String(), "\n"))
+	}
+	return success
+}
@@ -0,0 +1,126 @@
+package assert
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+)
+
+// recorder records what is reported to a test instead of failing it
+type recorder struct {
+	testing.TB
+
+	// name is the name of the test
+	name string
+
+	// errors are the errors reported to the test
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Name() string {
+	return r.name
+}
+
+func (r *recorder) Log(args ...interface{}) {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestOnce(t *testing.T) {
+	testCases := []struct {
+		name            string
+		command         string
+		options         []Option
+		expectedSuccess bool
+		expectedError   string
+	}{
+		{
+			name:            "succeeding command",
+			command:         "echo hello",
+			options:         []Option{Success(), StdoutContains(regexp.MustCompile(`hel+o`))},
+			expectedSuccess: true,
+		},
+		{
+			name:          "command with unexpected output",
+			command:       "echo hello",
+			options:       []Option{StdoutContains(regexp.MustCompile(`goodbye`))},
+			expectedError: "FAILURE after",
+		},
+		{
+			name:          "summary is named after the test",
+			command:       "exit 1",
+			expectedError: "TestSomething/subtest: executing `exit 1` once, expecting success",
+		},
+		{
+			name:            "command with expected exit code and output to stderr",
+			command:         "echo oops >&2; exit 3",
+			options:         []Option{ExitCode(2, 3), StderrContains(regexp.MustCompile(`oops`)), StdoutExcludes(regexp.MustCompile(`.`))},
+			expectedSuccess: true,
+		},
+		{
+			name:            "command killed by a signal",
+			command:         "kill -TERM $$",
+			options:         []Option{Signaled(syscall.SIGTERM)},
+			expectedSuccess: true,
+		},
+		{
+			name:            "command with a typed assertion",
+			command:         "echo a; echo b",
+			options:         []Option{Assert(api.Assertion{Kind: api.OutputAssertionLineCount, Target: api.OutputTargetStdout, Options: api.AssertionOptions{Count: api.CountComparison{Operator: api.CountOperatorExactly, Count: 2}}})},
+			expectedSuccess: true,
+		},
+		{
+			name:          "invalid configuration",
+			command:       "true",
+			options:       []Option{Assert(api.Assertion{Kind: "bogus"})},
+			expectedError: "Error configuring test",
+		},
+	}
+
+	for _, testCase := range testCases {
+		r := &recorder{name: "TestSomething/subtest"}
+		if expected, actual := testCase.expectedSuccess, Once(r, testCase.command, testCase.options...); expected != actual {
+			t.Errorf("%s: expected success %v, got %v: %v", testCase.name, expected, actual, r.errors)
+		}
+		if len(testCase.expectedError) == 0 && len(r.errors) > 0 {
+			t.Errorf("%s: expected no errors, got %v", testCase.name, r.errors)
+		}
+		if len(testCase.expectedError) > 0 && (len(r.errors) != 1 || !strings.Contains(r.errors[0], testCase.expectedError)) {
+			t.Errorf("%s: expected one error containing %q, got %v", testCase.name, testCase.expectedError, r.errors)
+		}
+	}
+}
+
+func TestEventually(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eventually")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// the command fails the first time it runs, as it only leaves the marker behind
+	marker := filepath.Join(dir, "marker")
+	r := &recorder{name: "TestEventually"}
+	if !Eventually(r, fmt.Sprintf("test -e %[1]s || { touch %[1]s; exit 1; }", marker), Timeout(5*time.Second), Interval(10*time.Millisecond)) {
+		t.Errorf("expected the command to eventually succeed, got %v", r.errors)
+	}
+
+	r = &recorder{name: "TestEventually"}
+	if Eventually(r, "exit 1", Timeout(100*time.Millisecond), Interval(10*time.Millisecond)) {
+		t.Errorf("expected the command to never succeed")
+	}
+	if len(r.errors) != 1 || !strings.Contains(r.errors[0], "the command timed out waiting for assertions to be met") {
+		t.Errorf("expected one error explaining the timeout, got %v", r.errors)
+	}
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+	"github.com/stevekuznetsov/exec-assert/pkg/command"
+	"github.com/stevekuznetsov/exec-assert/pkg/output"
+	"github.com/stevekuznetsov/exec-assert/pkg/summarizer"
+)
+
+// NewBackoffBuilder returns a new Builder that configures a test for executing a command once or more, growing the
+// interval between executions by the multiplier up to the maximum interval, with the given fraction of jitter, until
+// the given number of consecutive executions meet the assertions
+func NewBackoffBuilder(multiplier float64, maxInterval time.Duration, jitter float64, consecutive int) Builder {
+	return &backoffBuilder{multiplier: multiplier, maxInterval: maxInterval, jitter: jitter, consecutive: consecutive}
+}
+
+// backoffBuilder knows how to build the ExecutorAsserter and Reporter for a test with the ExecutionStrategyBackoff
+type backoffBuilder struct {
+	// multiplier is the factor by which the interval grows after each execution
+	multiplier float64
+
+	// maxInterval is the longest interval between executions
+	maxInterval time.Duration
+
+	// jitter is the fraction of each interval by which it is randomly lengthened or shortened
+	jitter float64
+
+	// consecutive is how many back-to-back executions must meet the assertions
+	consecutive int
+}
+
+// BuildExecutorAsserter builds an ExecutorAsserter with the given configuration, where the interval is the first
+// interval between executions
+func (b *backoffBuilder) BuildExecutorAsserter(cmd string, resultAssertion api.ResultAssertion, resultArguments api.ResultAssertionArguments, timeout, interval, attemptTimeout, gracePeriod time.Duration, outputTesters []output.Tester) ExecutorAsserter {
+	resultTester := buildResultTester(resultAssertion, resultArguments)
+	executor := command.NewBackoffExecutor(cmd, resultTester, outputTesters, timeout, interval, b.maxInterval, b.multiplier, b.jitter, attemptTimeout, gracePeriod, b.consecutive)
+	return NewRepeatedExecutorAsserter(executor, b.consecutive)
+}
+
+// BuildReporter builds a Reporter that reports on the test as text, rendered by the Renderer
+func (b *backoffBuilder) BuildReporter(renderer summarizer.Renderer) summarizer.Reporter {
+	return &summarizer.UntilDeclarerSummarizer{Renderer: renderer}
+}
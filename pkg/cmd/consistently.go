@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+	"github.com/stevekuznetsov/exec-assert/pkg/command"
+	"github.com/stevekuznetsov/exec-assert/pkg/output"
+	"github.com/stevekuznetsov/exec-assert/pkg/summarizer"
+)
+
+// NewConsistentlyBuilder returns a new Builder that configures a test for executing a command repeatedly, expecting the
+// assertions to hold every time
+func NewConsistentlyBuilder() Builder {
+	return &consistentlyBuilder{}
+}
+
+// consistentlyBuilder knows how to build the ExecutorAsserter and Reporter for a test with the ExecutionStrategyConsistently
+type consistentlyBuilder struct{}
+
+// BuildExecutorAsserter builds an ExecutorAsserter with the given configuration
+func (b *consistentlyBuilder) BuildExecutorAsserter(cmd string, resultAssertion api.ResultAssertion, resultArguments api.ResultAssertionArguments, timeout, interval, attemptTimeout, gracePeriod time.Duration, outputTesters []output.Tester) ExecutorAsserter {
+	resultTester := buildResultTester(resultAssertion, resultArguments)
+	executor := command.NewConsistentlyExecutor(cmd, resultTester, outputTesters, timeout, interval, attemptTimeout, gracePeriod)
+	// the executor stops at the first execution that breaks the assertions, so the verdicts on the last execution hold
+	// for them all
+	return NewRepeatedExecutorAsserter(executor, 0)
+}
+
+// BuildReporter builds a Reporter that reports on the test as text, rendered by the Renderer
+func (b *consistentlyBuilder) BuildReporter(renderer summarizer.Renderer) summarizer.Reporter {
+	return &summarizer.ConsistentlyDeclarerSummarizer{Renderer: renderer}
+}
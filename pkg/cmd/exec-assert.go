@@ -1,12 +1,15 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
 	"github.com/stevekuznetsov/exec-assert/pkg/api"
 	"github.com/stevekuznetsov/exec-assert/pkg/command"
 	"github.com/stevekuznetsov/exec-assert/pkg/output"
 	"github.com/stevekuznetsov/exec-assert/pkg/result"
+	"github.com/stevekuznetsov/exec-assert/pkg/util"
 )
 
 func NewExecutorAsserter(commandExecutor command.Executor, resultTester result.Tester, outputTesters []output.Tester) *executorAsserter {
@@ -17,38 +20,78 @@ func NewExecutorAsserter(commandExecutor command.Executor, resultTester result.T
 	}
 }
 
+// NewRepeatedExecutorAsserter returns an ExecutorAsserter for an executor that tests the assertions on every execution
+// of the command itself, requiring that the given number of executions in a row met them if more than one
+func NewRepeatedExecutorAsserter(commandExecutor command.Executor, consecutive int) *executorAsserter {
+	return &executorAsserter{
+		commandExecutor: commandExecutor,
+		consecutive:     consecutive,
+	}
+}
+
 // executorAsserter is able to run a bash command and make assertions about the
 // result of the execution as well as any output to stdout or stderr.
 type executorAsserter struct {
 	// commandExecutor executes the command and collects the output to stdout and stderr
 	commandExecutor command.Executor
 
-	// resultTester tests the result of the command execution
+	// resultTester tests the result of the command execution, unless the executor tests every execution itself
 	resultTester result.Tester
 
-	// outputTesters test the output of the command execution
+	// outputTesters test the output of the command execution, unless the executor tests every execution itself
 	outputTesters []output.Tester
+
+	// consecutive is how many back-to-back executions must have met the assertions for them to be met overall,
+	// if more than one
+	consecutive int
 }
 
-func (e *executorAsserter) ExecuteAndAssert() (api.ExecutionAssertionResults, error) {
-	duration, result, stdout, stderr, err := e.commandExecutor.Execute()
+func (e *executorAsserter) ExecuteAndAssert(ctx context.Context) (api.ExecutionAssertionResults, error) {
+	duration, result, attempts, err := e.commandExecutor.Execute(ctx)
 	if err != nil {
 		return api.ExecutionAssertionResults{}, fmt.Errorf("command execution failed: %v", err)
 	}
+	if len(attempts) == 0 {
+		return api.ExecutionAssertionResults{}, errors.New("command execution failed: the command was never executed")
+	}
+
+	// the assertions are judged on the last execution, as it is the one that ended the test
+	last := &attempts[len(attempts)-1]
+	if e.resultTester != nil {
+		// the executor does not test its executions, so we test the only one
+		last.ResultVerdict = e.resultTester.Test(result)
+		last.OutputVerdicts = make([]api.Verdict, len(e.outputTesters))
+		for i, tester := range e.outputTesters {
+			last.OutputVerdicts[i] = output.TestAttempt(tester, *last)
+		}
+	}
 
-	resultTestSuccess := e.resultTester.Test(result)
 	outputTestSuccess := true
-	for _, tester := range e.outputTesters {
+	for _, verdict := range last.OutputVerdicts {
 		// all testers need to succeed to succeed overall
-		outputTestSuccess = outputTestSuccess && tester.Test(stdout, stderr)
+		outputTestSuccess = outputTestSuccess && verdict.Passed
 	}
 
-	return api.ExecutionAssertionResults{
+	results := api.ExecutionAssertionResults{
 		Duration:        duration,
 		Result:          result,
-		ResultAssertion: resultTestSuccess,
-		Stdout:          stdout,
-		Stderr:          stderr,
+		ResultAssertion: last.ResultVerdict.Passed,
+		ResultVerdict:   last.ResultVerdict,
+		Stdout:          last.Stdout,
+		Stderr:          last.Stderr,
+		Combined:        last.Combined,
 		OutputAssertion: outputTestSuccess,
-	}, nil
+		OutputVerdicts:  last.OutputVerdicts,
+		Attempts:        attempts,
+	}
+
+	if e.consecutive > 1 {
+		longest, trailing := util.Streaks(attempts)
+		results.ConsecutiveVerdict = &api.Verdict{
+			Passed: trailing >= e.consecutive,
+			Reason: fmt.Sprintf("the longest streak was %d of %d executions in a row", longest, e.consecutive),
+		}
+	}
+
+	return results, nil
 }
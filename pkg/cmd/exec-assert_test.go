@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+	"github.com/stevekuznetsov/exec-assert/pkg/output"
+	"github.com/stevekuznetsov/exec-assert/pkg/result"
+	"github.com/stevekuznetsov/exec-assert/pkg/util"
+)
+
+// fakeExecutor returns the attempts it was given as though it had executed the command
+type fakeExecutor struct {
+	attempts []api.Attempt
+}
+
+func (e *fakeExecutor) Execute(context.Context) (time.Duration, error, []api.Attempt, error) {
+	if len(e.attempts) == 1 {
+		// like executing once, a single execution has a result of its own
+		return time.Second, e.attempts[0].Result, e.attempts, nil
+	}
+	var results []error
+	for _, attempt := range e.attempts {
+		results = append(results, attempt.Result)
+	}
+	return time.Second, util.NewCompoundResult(results), e.attempts, nil
+}
+
+func TestExecutorAsserter(t *testing.T) {
+	met := api.Verdict{Passed: true, Reason: "met"}
+	broken := api.Verdict{Passed: false, Reason: "broken"}
+
+	testCases := []struct {
+		name                       string
+		asserter                   *executorAsserter
+		expectedResultVerdict      api.Verdict
+		expectedOutputVerdicts     []api.Verdict
+		expectedConsecutiveVerdict *api.Verdict
+		expectedPassed             bool
+	}{
+		{
+			name: "the only execution is tested",
+			asserter: NewExecutorAsserter(
+				&fakeExecutor{attempts: []api.Attempt{{Stdout: "ready"}}},
+				result.NewSuccessTester(),
+				[]output.Tester{output.NewContainsTester(api.OutputTargetStdout, regexp.MustCompile("ready"))},
+			),
+			expectedResultVerdict:  api.Verdict{Passed: true, Reason: "the command exited with code 0"},
+			expectedOutputVerdicts: []api.Verdict{{Passed: true, Reason: "Command output to stdout contained `ready` on line 1", Spans: []api.Span{{Target: api.OutputTargetStdout, Start: 0, End: 5, Line: 1, Text: "ready"}}}},
+			expectedPassed:         true,
+		},
+		{
+			name: "the verdicts of the executor on the last execution are used",
+			asserter: NewRepeatedExecutorAsserter(&fakeExecutor{attempts: []api.Attempt{
+				{ResultVerdict: met, OutputVerdicts: []api.Verdict{broken}},
+				{ResultVerdict: met, OutputVerdicts: []api.Verdict{met}},
+			}}, 0),
+			expectedResultVerdict:  met,
+			expectedOutputVerdicts: []api.Verdict{met},
+			expectedPassed:         true,
+		},
+		{
+			name: "too few executions in a row met the assertions",
+			asserter: NewRepeatedExecutorAsserter(&fakeExecutor{attempts: []api.Attempt{
+				{ResultVerdict: met, OutputVerdicts: []api.Verdict{met}},
+				{ResultVerdict: met, OutputVerdicts: []api.Verdict{met}},
+				{ResultVerdict: broken, OutputVerdicts: []api.Verdict{met}},
+				{ResultVerdict: met, OutputVerdicts: []api.Verdict{met}},
+			}}, 3),
+			expectedResultVerdict:      met,
+			expectedOutputVerdicts:     []api.Verdict{met},
+			expectedConsecutiveVerdict: &api.Verdict{Passed: false, Reason: "the longest streak was 2 of 3 executions in a row"},
+			expectedPassed:             false,
+		},
+		{
+			name: "enough executions in a row met the assertions",
+			asserter: NewRepeatedExecutorAsserter(&fakeExecutor{attempts: []api.Attempt{
+				{ResultVerdict: broken, OutputVerdicts: []api.Verdict{met}},
+				{ResultVerdict: met, OutputVerdicts: []api.Verdict{met}},
+				{ResultVerdict: met, OutputVerdicts: []api.Verdict{met}},
+			}}, 2),
+			expectedResultVerdict:      met,
+			expectedOutputVerdicts:     []api.Verdict{met},
+			expectedConsecutiveVerdict: &api.Verdict{Passed: true, Reason: "the longest streak was 2 of 2 executions in a row"},
+			expectedPassed:             true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		results, err := testCase.asserter.ExecuteAndAssert(context.Background())
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", testCase.name, err)
+			continue
+		}
+		if expected, actual := testCase.expectedResultVerdict, results.ResultVerdict; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("%s: expected result verdict %#v, got %#v", testCase.name, expected, actual)
+		}
+		if expected, actual := testCase.expectedOutputVerdicts, results.OutputVerdicts; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("%s: expected output verdicts %#v, got %#v", testCase.name, expected, actual)
+		}
+		if expected, actual := testCase.expectedConsecutiveVerdict, results.ConsecutiveVerdict; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("%s: expected consecutive verdict %#v, got %#v", testCase.name, expected, actual)
+		}
+		if expected, actual := testCase.expectedPassed, util.Passed(results); expected != actual {
+			t.Errorf("%s: expected passed to be %v, got %v", testCase.name, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+	"github.com/stevekuznetsov/exec-assert/pkg/util"
+)
+
+// OutputFlags holds the output assertions as they are written on the command line, as delimited lists
+type OutputFlags struct {
+	// OutputAssertions is a comma-delimited list of output assertions, each optionally prefixed with
+	// the stream it targets, like `stderr:contains`
+	OutputAssertions string
+
+	// OutputTests are the tests for the OutputAssertions, split on the Delimiter if there is more than one
+	OutputTests string
+
+	// Delimiter is the delimiter to use when parsing the lists of OutputTests and JSONTests
+	Delimiter string
+
+	// Match is how the OutputTests are matched, either one mode for every test or a comma-delimited
+	// list of modes, one for each test
+	Match string
+
+	// JSONTests are queries that must hold on the output to stdout parsed as JSON, split on the
+	// Delimiter if there is more than one, like `.items | length >= 3`
+	JSONTests string
+
+	// GoldenFiles is a comma-delimited list of golden files the output must match exactly, each
+	// optionally prefixed with the stream it targets, like `stderr:path`
+	GoldenFiles string
+
+	// UpdateGoldenFiles determines if golden files should be rewritten with the output instead
+	UpdateGoldenFiles bool
+}
+
+// Assertions converts the flags to the assertions they make
+func (f OutputFlags) Assertions() ([]api.Assertion, error) {
+	var assertions []api.Assertion
+	if len(f.OutputAssertions) > 0 {
+		outputAssertions, err := f.outputAssertions()
+		if err != nil {
+			return nil, err
+		}
+		assertions = append(assertions, outputAssertions...)
+	}
+
+	if len(f.GoldenFiles) > 0 {
+		for _, goldenFile := range strings.Split(f.GoldenFiles, ",") {
+			target, path := api.OutputTarget(api.OutputTargetStdout), goldenFile
+			if parts := strings.SplitN(goldenFile, ":", 2); len(parts) == 2 {
+				target, path = api.OutputTarget(parts[0]), parts[1]
+				if target == api.OutputTargetAny {
+					return nil, fmt.Errorf("unrecognized golden file target: got %q, expected one of %s", parts[0], []api.OutputTarget{api.OutputTargetStdout, api.OutputTargetStderr, api.OutputTargetCombined})
+				}
+			}
+			assertions = append(assertions, api.Assertion{Kind: api.OutputAssertionGolden, Target: target, Pattern: path, Options: api.AssertionOptions{Update: f.UpdateGoldenFiles}})
+		}
+	}
+
+	if len(f.JSONTests) > 0 {
+		jsonTests := []string{f.JSONTests}
+		if len(f.Delimiter) > 0 {
+			jsonTests = strings.Split(f.JSONTests, f.Delimiter)
+		}
+		for _, jsonTest := range jsonTests {
+			assertions = append(assertions, api.Assertion{Kind: api.OutputAssertionJSON, Pattern: jsonTest})
+		}
+	}
+
+	return assertions, nil
+}
+
+// outputAssertions pairs each output assertion with its test and match mode
+func (f OutputFlags) outputAssertions() ([]api.Assertion, error) {
+	outputAssertions := strings.Split(f.OutputAssertions, ",")
+
+	tests := []string{f.OutputTests}
+	if len(f.Delimiter) > 0 {
+		tests = strings.Split(f.OutputTests, f.Delimiter)
+	}
+
+	matchModes, err := parseMatchModes(f.Match, len(tests))
+	if err != nil {
+		return nil, err
+	}
+
+	// an in-order assertion takes every test that is not paired with another assertion, so that the tests for
+	// the other assertions keep their positions in the list
+	inOrder := 0
+	for _, outputAssertion := range outputAssertions {
+		if _, assertion := splitOutputTarget(outputAssertion); assertion == "in-order" {
+			inOrder++
+		}
+	}
+	if inOrder > 1 {
+		return nil, errors.New("only one in-order output assertion can be made")
+	}
+
+	sequenceLength := 1
+	if inOrder == 1 {
+		sequenceLength = len(tests) - len(outputAssertions) + 1
+	}
+	if sequenceLength < 1 || (inOrder == 0 && len(tests) != len(outputAssertions)) {
+		return nil, fmt.Errorf("the number of output assertions and output tests don't match: assertions: %s, tests: %s", outputAssertions, tests)
+	}
+
+	var assertions []api.Assertion
+	for _, outputAssertion := range outputAssertions {
+		target, name := splitOutputTarget(outputAssertion)
+		kind, count, err := util.ParseOutputAssertion(name)
+		if err != nil {
+			return nil, err
+		}
+
+		assertion := api.Assertion{Kind: kind, Target: target, Pattern: tests[0], Options: api.AssertionOptions{Match: matchModes[0], Count: count}}
+		if kind == api.OutputAssertionInOrder {
+			assertion.Pattern = ""
+			assertion.Options.Sequence = tests[:sequenceLength]
+			for _, matchMode := range matchModes[:sequenceLength] {
+				if matchMode != matchModes[0] {
+					return nil, errors.New("every test of an in-order output assertion must be matched the same way")
+				}
+			}
+			tests, matchModes = tests[sequenceLength:], matchModes[sequenceLength:]
+		} else {
+			tests, matchModes = tests[1:], matchModes[1:]
+		}
+		assertions = append(assertions, assertion)
+	}
+	return assertions, nil
+}
+
+// splitOutputTarget splits an output assertion into the stream it targets, if any, and the assertion itself
+func splitOutputTarget(outputAssertion string) (api.OutputTarget, string) {
+	if parts := strings.SplitN(outputAssertion, ":", 2); len(parts) == 2 {
+		return api.OutputTarget(parts[0]), parts[1]
+	}
+	return api.OutputTargetAny, outputAssertion
+}
+
+// parseMatchModes parses how each of the output tests is matched, from either one mode for every test or a
+// comma-delimited list of modes, one for each test
+func parseMatchModes(value string, tests int) ([]api.MatchMode, error) {
+	if len(value) == 0 {
+		value = api.MatchModeRegex
+	}
+
+	var matchModes []api.MatchMode
+	for _, matchMode := range strings.Split(value, ",") {
+		switch matchMode {
+		case "literal":
+			matchModes = append(matchModes, api.MatchModeLiteral)
+		case "glob":
+			matchModes = append(matchModes, api.MatchModeGlob)
+		case "regex":
+			matchModes = append(matchModes, api.MatchModeRegex)
+		default:
+			return nil, fmt.Errorf("unrecognized match mode: got %q, expected one of %s", matchMode, api.ValidMatchModes)
+		}
+	}
+
+	if len(matchModes) == 1 {
+		for len(matchModes) < tests {
+			matchModes = append(matchModes, matchModes[0])
+		}
+	}
+
+	if len(matchModes) != tests {
+		return nil, fmt.Errorf("the number of match modes and output tests don't match: modes: %s, tests: %d", matchModes, tests)
+	}
+	return matchModes, nil
+}
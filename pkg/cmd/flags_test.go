@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+)
+
+func TestOutputFlagsAssertions(t *testing.T) {
+	testCases := []struct {
+		name               string
+		flags              OutputFlags
+		expectedAssertions []api.Assertion
+		expectedError      bool
+	}{
+		{
+			name:               "default flags",
+			flags:              OutputFlags{OutputAssertions: "ambivalent", Match: "regex"},
+			expectedAssertions: []api.Assertion{{Kind: api.OutputAssertionAmbivalent, Target: api.OutputTargetAny, Options: api.AssertionOptions{Match: api.MatchModeRegex}}},
+		},
+		{
+			name:  "targeted assertions with tests and match modes",
+			flags: OutputFlags{OutputAssertions: "stderr:contains,excludes", OutputTests: "error#panic", Delimiter: "#", Match: "literal,glob"},
+			expectedAssertions: []api.Assertion{
+				{Kind: api.OutputAssertionContains, Target: api.OutputTargetStderr, Pattern: "error", Options: api.AssertionOptions{Match: api.MatchModeLiteral}},
+				{Kind: api.OutputAssertionExcludes, Target: api.OutputTargetAny, Pattern: "panic", Options: api.AssertionOptions{Match: api.MatchModeGlob}},
+			},
+		},
+		{
+			name:  "counting assertions",
+			flags: OutputFlags{OutputAssertions: "contains>=2,stdout:lines<=3", OutputTests: "x#", Delimiter: "#"},
+			expectedAssertions: []api.Assertion{
+				{Kind: api.OutputAssertionMatchCount, Target: api.OutputTargetAny, Pattern: "x", Options: api.AssertionOptions{Match: api.MatchModeRegex, Count: api.CountComparison{Operator: api.CountOperatorAtLeast, Count: 2}}},
+				{Kind: api.OutputAssertionLineCount, Target: api.OutputTargetStdout, Options: api.AssertionOptions{Match: api.MatchModeRegex, Count: api.CountComparison{Operator: api.CountOperatorAtMost, Count: 3}}},
+			},
+		},
+		{
+			name:  "in-order assertion takes the tests not paired with other assertions",
+			flags: OutputFlags{OutputAssertions: "excludes,in-order,stderr:empty", OutputTests: "panic#a#b#c#", Delimiter: "#"},
+			expectedAssertions: []api.Assertion{
+				{Kind: api.OutputAssertionExcludes, Target: api.OutputTargetAny, Pattern: "panic", Options: api.AssertionOptions{Match: api.MatchModeRegex}},
+				{Kind: api.OutputAssertionInOrder, Target: api.OutputTargetAny, Options: api.AssertionOptions{Match: api.MatchModeRegex, Sequence: []string{"a", "b", "c"}}},
+				{Kind: api.OutputAssertionLineCount, Target: api.OutputTargetStderr, Options: api.AssertionOptions{Match: api.MatchModeRegex, Count: api.CountComparison{Operator: api.CountOperatorExactly, Count: 0}}},
+			},
+		},
+		{
+			name:  "golden files and JSON tests",
+			flags: OutputFlags{GoldenFiles: "out.golden,stderr:err.golden", UpdateGoldenFiles: true, JSONTests: ".a#.b", Delimiter: "#"},
+			expectedAssertions: []api.Assertion{
+				{Kind: api.OutputAssertionGolden, Target: api.OutputTargetStdout, Pattern: "out.golden", Options: api.AssertionOptions{Update: true}},
+				{Kind: api.OutputAssertionGolden, Target: api.OutputTargetStderr, Pattern: "err.golden", Options: api.AssertionOptions{Update: true}},
+				{Kind: api.OutputAssertionJSON, Pattern: ".a"},
+				{Kind: api.OutputAssertionJSON, Pattern: ".b"},
+			},
+		},
+		{
+			name:          "more assertions than tests",
+			flags:         OutputFlags{OutputAssertions: "contains,contains", OutputTests: "a"},
+			expectedError: true,
+		},
+		{
+			name:          "more than one in-order assertion",
+			flags:         OutputFlags{OutputAssertions: "in-order,in-order", OutputTests: "a#b", Delimiter: "#"},
+			expectedError: true,
+		},
+		{
+			name:          "in-order assertion with tests matched different ways",
+			flags:         OutputFlags{OutputAssertions: "in-order", OutputTests: "a#b", Delimiter: "#", Match: "literal,glob"},
+			expectedError: true,
+		},
+		{
+			name:          "unrecognized match mode",
+			flags:         OutputFlags{OutputAssertions: "contains", OutputTests: "a", Match: "fuzzy"},
+			expectedError: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		assertions, err := testCase.flags.Assertions()
+		if expected, actual := testCase.expectedError, err != nil; expected != actual {
+			t.Errorf("%s: expected error %v, got %v", testCase.name, expected, err)
+			continue
+		}
+		if expected, actual := testCase.expectedAssertions, assertions; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("%s: did not convert flags to correct assertions:\nexpected: %#v\ngot:      %#v", testCase.name, expected, actual)
+		}
+	}
+}
@@ -1,27 +1,26 @@
 package cmd
 
 import (
-	"regexp"
+	"context"
 	"time"
 
 	"github.com/stevekuznetsov/exec-assert/pkg/api"
+	"github.com/stevekuznetsov/exec-assert/pkg/output"
 	"github.com/stevekuznetsov/exec-assert/pkg/summarizer"
 )
 
 // ExecutorAsserter executes a command and evaluates some assertions about the execution
 type ExecutorAsserter interface {
-	// ExecuteAndAssert executes a command and evaluates some assertions, returning command results, output, and assertion test output
-	ExecuteAndAssert() (results api.ExecutionAssertionResults, err error)
+	// ExecuteAndAssert executes a command and evaluates some assertions, returning command results, output, and assertion test output.
+	// Any command still running when the context is done is killed.
+	ExecuteAndAssert(ctx context.Context) (results api.ExecutionAssertionResults, err error)
 }
 
-// Builder knows how to build the ExecutorAsserter as well as a Declarer and Summarizer
+// Builder knows how to build the ExecutorAsserter as well as a Reporter
 type Builder interface {
 	// BuildExecutorAsserter builds an ExecutorAsserter with the given configuration
-	BuildExecutorAsserter(command string, resultAssertion api.ResultAssertion, timeout, interval time.Duration, outputAssertion []api.OutputAssertion, outputTest []*regexp.Regexp) ExecutorAsserter
+	BuildExecutorAsserter(command string, resultAssertion api.ResultAssertion, resultArguments api.ResultAssertionArguments, timeout, interval, attemptTimeout, gracePeriod time.Duration, outputTesters []output.Tester) ExecutorAsserter
 
-	// BuildDeclarer builds a Declarer for the test
-	BuildDeclarer() summarizer.Declarer
-
-	// BuildSummarizer builds a Summarizer for the test
-	BuildSummarizer() summarizer.Summarizer
+	// BuildReporter builds a Reporter that reports on the test as text, rendered by the Renderer
+	BuildReporter(renderer summarizer.Renderer) summarizer.Reporter
 }
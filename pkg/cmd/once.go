@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"regexp"
 	"time"
 
 	"github.com/stevekuznetsov/exec-assert/pkg/api"
@@ -16,17 +15,19 @@ func NewOnceBuilder() Builder {
 	return &onceBuilder{}
 }
 
-// onceBuilder knows how to build the ExecutorAsserter, Declarer, and Summarizer for a test with the ExecutionStrategyOnce
-type onceBuilder struct {
-	declarerSummarizer *summarizer.OnceDeclarerSummarizer
-}
+// onceBuilder knows how to build the ExecutorAsserter and Reporter for a test with the ExecutionStrategyOnce
+type onceBuilder struct{}
 
 // BuildExecutorAsserter builds an ExecutorAsserter with the given configuration
-func (b *onceBuilder) BuildExecutorAsserter(cmd string, resultAssertion api.ResultAssertion, timeout, interval time.Duration, outputAssertion []api.OutputAssertion, outputTest []*regexp.Regexp) ExecutorAsserter {
-	return NewExecutorAsserter(command.NewOnceExecutor(cmd), buildResultTester(resultAssertion), buildOutputTesters(outputAssertion, outputTest))
+func (b *onceBuilder) BuildExecutorAsserter(cmd string, resultAssertion api.ResultAssertion, resultArguments api.ResultAssertionArguments, timeout, interval, attemptTimeout, gracePeriod time.Duration, outputTesters []output.Tester) ExecutorAsserter {
+	// when executing once, the only attempt is the whole execution, so it is bound by whichever deadline comes first
+	if timeout > 0 && (attemptTimeout == 0 || timeout < attemptTimeout) {
+		attemptTimeout = timeout
+	}
+	return NewExecutorAsserter(command.NewOnceExecutor(cmd, attemptTimeout, gracePeriod), buildResultTester(resultAssertion, resultArguments), outputTesters)
 }
 
-func buildResultTester(resultAssertion api.ResultAssertion) result.Tester {
+func buildResultTester(resultAssertion api.ResultAssertion, resultArguments api.ResultAssertionArguments) result.Tester {
 	switch resultAssertion {
 	case api.ResultAssertionSuccess:
 		return result.NewSuccessTester()
@@ -34,41 +35,15 @@ func buildResultTester(resultAssertion api.ResultAssertion) result.Tester {
 		return result.NewFailureTester()
 	case api.ResultAssertionAmbivalent:
 		return result.NewAmbivalentTester()
+	case api.ResultAssertionExitCode:
+		return result.NewExitCodeTester(resultArguments.ExitCodes)
+	case api.ResultAssertionSignaled:
+		return result.NewSignaledTester(resultArguments.Signal)
 	}
 	return nil
 }
 
-func buildOutputTesters(outputAssertions []api.OutputAssertion, tests []*regexp.Regexp) []output.Tester {
-	testers := []output.Tester{}
-
-	for i := 0; i < len(outputAssertions); i++ {
-		outputAssertion, test := outputAssertions[i], tests[i]
-		switch outputAssertion {
-		case api.OutputAssertionContains:
-			testers = append(testers, output.NewContainsTester(test))
-		case api.OutputAssertionExcludes:
-			testers = append(testers, output.NewExcludesTester(test))
-		case api.OutputAssertionAmbivalent:
-			testers = append(testers, output.NewAmbivalentTester())
-		}
-	}
-	return testers
-}
-
-// BuildDeclarer builds a Declarer for the test
-func (b *onceBuilder) BuildDeclarer() summarizer.Declarer {
-	if b.declarerSummarizer == nil {
-		b.declarerSummarizer = &summarizer.OnceDeclarerSummarizer{}
-	}
-
-	return b.declarerSummarizer
-}
-
-// BuildSummarizer builds a Summarizer for the test
-func (b *onceBuilder) BuildSummarizer() summarizer.Summarizer {
-	if b.declarerSummarizer == nil {
-		b.declarerSummarizer = &summarizer.OnceDeclarerSummarizer{}
-	}
-
-	return b.declarerSummarizer
+// BuildReporter builds a Reporter that reports on the test as text, rendered by the Renderer
+func (b *onceBuilder) BuildReporter(renderer summarizer.Renderer) summarizer.Reporter {
+	return &summarizer.OnceDeclarerSummarizer{Renderer: renderer}
 }
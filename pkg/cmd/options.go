@@ -1,14 +1,20 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
-	"regexp"
+	"math"
+	"os"
+	"strconv"
 	"strings"
+	"syscall"
 
 	"github.com/stevekuznetsov/exec-assert/pkg/api"
+	"github.com/stevekuznetsov/exec-assert/pkg/output"
 	"github.com/stevekuznetsov/exec-assert/pkg/summarizer"
+	"github.com/stevekuznetsov/exec-assert/pkg/util"
 )
 
 // ExecuteAssertOptions is able to run a bash command and make assertions about the
@@ -17,26 +23,51 @@ type ExecuteAssertOptions struct {
 	// Config is the configuration for the test
 	Config api.ExecutionAssertionConfig
 
+	// Flags are output assertions written as flags, which are added to the assertions in the Config
+	Flags OutputFlags
+
 	// executionStrategy is the strategy to use for execution
 	executionStrategy api.ExecutionStrategy
 
 	// resultAssertion is the assertion to make on command execution results
 	resultAssertion api.ResultAssertion
 
-	// outputAssertion is the assertion to make on command execution output
-	outputAssertions []api.OutputAssertion
+	// resultArguments are the arguments to the assertion to make on command execution results
+	resultArguments api.ResultAssertionArguments
 
-	// outputTest is the regex to test the command execution output with
-	outputTests []*regexp.Regexp
+	// outputTesters test the command execution output
+	outputTesters []output.Tester
 
 	// Output is the writer to which output should go
 	Output io.Writer
 
-	// declarer summarizes the test config for output
-	declarer summarizer.Declarer
+	// Declarations is the writer to which the declaration of the test should go before it runs; if
+	// it is not set, the declaration goes to the Output
+	Declarations io.Writer
+
+	// Format is the format in which the test is reported, if no Reporter is set
+	Format string
+
+	// Reporter reports on the test; if it is not set, a Reporter for the Format is used
+	Reporter summarizer.Reporter
+
+	// TestNumber is the number of the test in the suite it is run in, for formats that number tests,
+	// or zero if it is run on its own
+	TestNumber int
+
+	// JUnitPath is the path to a JUnit XML report to record the test in, if any; the test is added to
+	// any report already at the path
+	JUnitPath string
 
-	// summarizer summarizes the test result for output
-	summarizer summarizer.Summarizer
+	// Color determines when a text report is rendered in color: always, never, or automatically when the
+	// Output is a terminal and NO_COLOR is not set
+	Color string
+
+	// format is the format in which the test is reported
+	format api.ReportFormat
+
+	// colorMode determines when a text report is rendered in color
+	colorMode api.ColorMode
 }
 
 // Complete translates configuration options from the user to useful fields
@@ -46,6 +77,10 @@ func (o *ExecuteAssertOptions) Complete() error {
 		o.executionStrategy = api.ExecutionStrategyOnce
 	case "until":
 		o.executionStrategy = api.ExecutionStrategyUntil
+	case "backoff":
+		o.executionStrategy = api.ExecutionStrategyBackoff
+	case "consistently":
+		o.executionStrategy = api.ExecutionStrategyConsistently
 	default:
 		return fmt.Errorf("unrecognized execution strategy, got %q, expected one of %s", o.Config.ExecutionStrategy, api.ValidExecutionStrategies)
 	}
@@ -57,40 +92,142 @@ func (o *ExecuteAssertOptions) Complete() error {
 		o.resultAssertion = api.ResultAssertionFailure
 	case "ambivalent":
 		o.resultAssertion = api.ResultAssertionAmbivalent
+	case "signaled":
+		// a signaled assertion without a signal expects any signal, and is described as such
+		o.Config.ResultAssertion = api.ResultAssertionSignaled + "=any"
+		o.resultAssertion = api.ResultAssertionSignaled
 	default:
-		return fmt.Errorf("unrecognized result assertion: got %q, expected one of %s", o.Config.ResultAssertion, api.ValidResultAssertions)
-	}
-
-	outputAssertions := strings.Split(o.Config.OutputAssertions, ",")
-	for _, outputAssertion := range outputAssertions {
-		switch outputAssertion {
-		case "contains":
-			o.outputAssertions = append(o.outputAssertions, api.OutputAssertionContains)
-		case "excludes":
-			o.outputAssertions = append(o.outputAssertions, api.OutputAssertionExcludes)
-		case "ambivalent":
-			o.outputAssertions = append(o.outputAssertions, api.OutputAssertionAmbivalent)
+		switch {
+		case strings.HasPrefix(o.Config.ResultAssertion, api.ResultAssertionExitCode):
+			exitCodes, err := parseExitCodes(strings.TrimPrefix(o.Config.ResultAssertion, api.ResultAssertionExitCode))
+			if err != nil {
+				return fmt.Errorf("failed to parse exit code result assertion %q: %v", o.Config.ResultAssertion, err)
+			}
+			o.resultAssertion = api.ResultAssertionExitCode
+			o.resultArguments.ExitCodes = exitCodes
+		case strings.HasPrefix(o.Config.ResultAssertion, api.ResultAssertionSignaled+"="):
+			signal, err := parseSignal(strings.TrimPrefix(o.Config.ResultAssertion, api.ResultAssertionSignaled+"="))
+			if err != nil {
+				return fmt.Errorf("failed to parse signaled result assertion %q: %v", o.Config.ResultAssertion, err)
+			}
+			o.resultAssertion = api.ResultAssertionSignaled
+			o.resultArguments.Signal = signal
 		default:
-			return fmt.Errorf("unrecognized output assertion: got %q, expected one of %s", outputAssertion, api.ValidOutputAssertions)
+			return fmt.Errorf("unrecognized result assertion: got %q, expected one of %s", o.Config.ResultAssertion, api.ValidResultAssertions)
 		}
 	}
 
-	var tests []string
-	if len(o.Config.Delimiter) > 0 {
-		tests = strings.Split(o.Config.OutputTests, o.Config.Delimiter)
-	} else {
-		tests = []string{o.Config.OutputTests}
+	switch o.Format {
+	case "", "text":
+		o.format = api.ReportFormatText
+	case "json":
+		o.format = api.ReportFormatJSON
+	case "tap":
+		o.format = api.ReportFormatTAP
+	default:
+		return fmt.Errorf("unrecognized format: got %q, expected one of %s", o.Format, api.ValidReportFormats)
 	}
 
-	for _, test := range tests {
-		compiledTest, err := regexp.Compile(test)
+	colorMode, err := parseColorMode(o.Color)
+	if err != nil {
+		return err
+	}
+	o.colorMode = colorMode
+
+	flagAssertions, err := o.Flags.Assertions()
+	if err != nil {
+		return err
+	}
+	o.Config.Assertions = append(o.Config.Assertions, flagAssertions...)
+
+	outputTesters, err := output.NewTesters(o.Config.Assertions)
+	if err != nil {
+		return err
+	}
+	o.outputTesters = outputTesters
+
+	return nil
+}
+
+// parseColorMode parses when to render text reports in color, which is automatic by default
+func parseColorMode(value string) (api.ColorMode, error) {
+	switch value {
+	case "", "auto":
+		return api.ColorModeAuto, nil
+	case "always":
+		return api.ColorModeAlways, nil
+	case "never":
+		return api.ColorModeNever, nil
+	default:
+		return "", fmt.Errorf("unrecognized color mode: got %q, expected one of %s", value, api.ValidColorModes)
+	}
+}
+
+// useColor determines if text reports written to the writer are rendered in color; automatically, color is
+// only used for a terminal, where it is seen rather than its escape codes, and never when NO_COLOR is set
+func useColor(mode api.ColorMode, writer io.Writer) bool {
+	switch mode {
+	case api.ColorModeAlways:
+		return true
+	case api.ColorModeNever:
+		return false
+	default:
+		return len(os.Getenv("NO_COLOR")) == 0 && util.IsTerminal(writer)
+	}
+}
+
+// parseExitCodes parses the set of exit codes given to an exit code result assertion, like `=3`, `=1,2`, `=64-78`
+// or `!=0`
+func parseExitCodes(value string) (api.ExitCodes, error) {
+	var exitCodes api.ExitCodes
+	switch {
+	case strings.HasPrefix(value, "!="):
+		exitCodes.Negated = true
+		value = strings.TrimPrefix(value, "!=")
+	case strings.HasPrefix(value, "="):
+		value = strings.TrimPrefix(value, "=")
+	default:
+		return api.ExitCodes{}, errors.New("expected the exit codes to follow `=` or `!=`")
+	}
+
+	for _, codes := range strings.Split(value, ",") {
+		bounds := strings.SplitN(codes, "-", 2)
+		min, err := parseExitCode(bounds[0])
 		if err != nil {
-			return fmt.Errorf("failed to compile output test %q to regular expression: %v", test, err)
+			return api.ExitCodes{}, err
+		}
+
+		max := min
+		if len(bounds) > 1 {
+			if max, err = parseExitCode(bounds[1]); err != nil {
+				return api.ExitCodes{}, err
+			}
 		}
-		o.outputTests = append(o.outputTests, compiledTest)
+
+		if max < min {
+			return api.ExitCodes{}, fmt.Errorf("exit code range %q ends before it begins", codes)
+		}
+		exitCodes.Ranges = append(exitCodes.Ranges, api.ExitCodeRange{Min: min, Max: max})
 	}
 
-	return nil
+	return exitCodes, nil
+}
+
+// parseExitCode parses one exit code
+func parseExitCode(value string) (int, error) {
+	code, err := strconv.Atoi(value)
+	if err != nil || code < 0 || code > 255 {
+		return 0, fmt.Errorf("exit code must be an integer between 0 and 255, got %q", value)
+	}
+	return code, nil
+}
+
+// parseSignal parses the signal given to a signaled result assertion, where `any` expects any signal
+func parseSignal(value string) (syscall.Signal, error) {
+	if value == "any" {
+		return 0, nil
+	}
+	return util.ParseSignal(value)
 }
 
 // Validate validates the test configuration
@@ -103,51 +240,126 @@ func (o *ExecuteAssertOptions) Validate() error {
 		return errors.New("execution interval must be a non-negative amount of seconds")
 	}
 
-	if o.Config.Timeout < o.Config.Interval {
+	if o.Config.AttemptTimeout < 0 {
+		return errors.New("execution attempt timeout must be a non-negative amount of seconds")
+	}
+
+	if o.Config.GracePeriod < 0 {
+		return errors.New("execution grace period must be a non-negative amount of seconds")
+	}
+
+	if o.Config.Context < 0 {
+		return errors.New("output context must be a non-negative number of lines")
+	}
+
+	if o.Config.Consecutive < 0 {
+		return errors.New("consecutive executions must be a non-negative number")
+	}
+
+	if o.Config.Consecutive > 1 && o.executionStrategy != api.ExecutionStrategyUntil && o.executionStrategy != api.ExecutionStrategyBackoff {
+		return fmt.Errorf("consecutive executions can only be required when executing with strategy %q or %q", api.ExecutionStrategyUntil, api.ExecutionStrategyBackoff)
+	}
+
+	if o.executionStrategy != api.ExecutionStrategyOnce && o.Config.Timeout < o.Config.Interval {
 		return errors.New("execution interval must be shorter than the execution timeout")
 	}
 
+	if o.executionStrategy == api.ExecutionStrategyBackoff {
+		if isNotFinite(o.Config.Multiplier) || o.Config.Multiplier < 1 {
+			return errors.New("backoff multiplier must be a finite number of at least 1")
+		}
+
+		if o.Config.MaxInterval < o.Config.Interval {
+			return errors.New("maximum execution interval must not be shorter than the execution interval")
+		}
+
+		if isNotFinite(o.Config.Jitter) || o.Config.Jitter < 0 || o.Config.Jitter > 1 {
+			return errors.New("backoff jitter must be a fraction between 0 and 1")
+		}
+	}
+
 	outputAssertionsMeaningful := false
-	for _, assertion := range o.outputAssertions {
-		if assertion != api.OutputAssertionAmbivalent {
+	for _, assertion := range o.Config.Assertions {
+		if assertion.Kind != api.OutputAssertionAmbivalent {
 			outputAssertionsMeaningful = true
 			break
 		}
 	}
 
-	if o.executionStrategy == api.ExecutionStrategyUntil && (o.resultAssertion == api.ResultAssertionAmbivalent && !outputAssertionsMeaningful) {
+	if o.executionStrategy != api.ExecutionStrategyOnce && (o.resultAssertion == api.ResultAssertionAmbivalent && !outputAssertionsMeaningful) {
 		return fmt.Errorf("if execuing with strategy %q, must provide at at least one assertion", o.executionStrategy)
 	}
 
-	if len(o.outputAssertions) != len(o.outputTests) {
-		return fmt.Errorf("the number of output assertions and output tests don't match: assertions: %s, tests: %s", o.outputAssertions, o.outputTests)
-	}
-
 	return nil
 }
 
-// Run runs the command, capturing output to stdout and stderr, then evaluates the assertions about the result and output of the command
-func (o *ExecuteAssertOptions) Run() (bool, error) {
+// isNotFinite determines if a number is NaN or infinite, which no comparison rules out on its own
+func isNotFinite(value float64) bool {
+	return math.IsNaN(value) || math.IsInf(value, 0)
+}
+
+// Run runs the command, capturing output to stdout and stderr, then evaluates the assertions about the result and output of the command.
+// The command and every process it started are killed if the context is done before it exits.
+func (o *ExecuteAssertOptions) Run(ctx context.Context) (bool, error) {
 	var builder Builder
 	switch o.executionStrategy {
 	case api.ExecutionStrategyOnce:
 		builder = NewOnceBuilder()
 	case api.ExecutionStrategyUntil:
-		builder = NewUntilBuilder()
+		builder = NewUntilBuilder(o.Config.Consecutive)
+	case api.ExecutionStrategyBackoff:
+		builder = NewBackoffBuilder(o.Config.Multiplier, o.Config.MaxInterval, o.Config.Jitter, o.Config.Consecutive)
+	case api.ExecutionStrategyConsistently:
+		builder = NewConsistentlyBuilder()
+	}
+
+	executorAsserter := builder.BuildExecutorAsserter(o.Config.Command, o.resultAssertion, o.resultArguments, o.Config.Timeout, o.Config.Interval, o.Config.AttemptTimeout, o.Config.GracePeriod, o.outputTesters)
+
+	// formats for other programs only use the text Reporter to describe failures, so they are never colored
+	renderer := summarizer.Renderer{Color: useColor(o.colorMode, o.Output), Context: o.Config.Context}
+	reporter := o.Reporter
+	if reporter == nil {
+		switch o.format {
+		case api.ReportFormatText:
+			reporter = builder.BuildReporter(renderer)
+		case api.ReportFormatJSON:
+			reporter = &summarizer.JSONReporter{}
+		case api.ReportFormatTAP:
+			reporter = summarizer.NewTAPReporter(builder.BuildReporter(summarizer.Renderer{}), o.TestNumber)
+		}
 	}
 
-	declarer := builder.BuildDeclarer()
-	executorAsserter := builder.BuildExecutorAsserter(o.Config.Command, o.resultAssertion, o.Config.Timeout, o.Config.Interval, o.outputAssertions, o.outputTests)
-	summarizer := builder.BuildSummarizer()
+	var junitReporter *summarizer.JUnitReporter
+	if len(o.JUnitPath) > 0 {
+		junitReporter = summarizer.NewJUnitReporter(reporter, builder.BuildReporter(summarizer.Renderer{}))
+		reporter = junitReporter
+	}
 
-	fmt.Fprint(o.Output, declarer.Declare(o.Config))
+	declarations := o.Declarations
+	if declarations == nil {
+		declarations = o.Output
+	}
+	fmt.Fprint(declarations, reporter.Declare(o.Config))
 
-	results, err := executorAsserter.ExecuteAndAssert()
+	results, err := executorAsserter.ExecuteAndAssert(ctx)
 	if err != nil {
-		return false, fmt.Errorf("command execution failed: %v", err)
+		err = fmt.Errorf("command execution failed: %v", err)
+		if junitReporter != nil {
+			junitReporter.Fail(err)
+			if err := summarizer.AppendJUnitTestCase(o.JUnitPath, junitReporter.TestCase()); err != nil {
+				return false, err
+			}
+		}
+		return false, err
 	}
 
-	fmt.Fprint(o.Output, summarizer.Summarize(results, o.Config.Verbose))
+	fmt.Fprint(o.Output, reporter.Summarize(results, o.Config.Verbose))
+
+	if junitReporter != nil {
+		if err := summarizer.AppendJUnitTestCase(o.JUnitPath, junitReporter.TestCase()); err != nil {
+			return false, err
+		}
+	}
 
-	return results.ResultAssertion && results.OutputAssertion, nil
+	return util.Passed(results), nil
 }
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+)
+
+func TestValidateBackoff(t *testing.T) {
+	testCases := []struct {
+		name          string
+		multiplier    float64
+		jitter        float64
+		expectedError bool
+	}{
+		{
+			name:       "finite multiplier and jitter",
+			multiplier: 2,
+			jitter:     0.5,
+		},
+		{
+			name:          "multiplier below 1",
+			multiplier:    0.5,
+			expectedError: true,
+		},
+		{
+			name:          "NaN multiplier",
+			multiplier:    math.NaN(),
+			expectedError: true,
+		},
+		{
+			name:          "infinite multiplier",
+			multiplier:    math.Inf(1),
+			expectedError: true,
+		},
+		{
+			name:          "NaN jitter",
+			multiplier:    2,
+			jitter:        math.NaN(),
+			expectedError: true,
+		},
+		{
+			name:          "infinite jitter",
+			multiplier:    2,
+			jitter:        math.Inf(-1),
+			expectedError: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		options := ExecuteAssertOptions{
+			Config: api.ExecutionAssertionConfig{
+				Command:           "false",
+				ExecutionStrategy: "backoff",
+				ResultAssertion:   "success",
+				Timeout:           2 * time.Second,
+				Interval:          100 * time.Millisecond,
+				Multiplier:        testCase.multiplier,
+				MaxInterval:       time.Second,
+				Jitter:            testCase.jitter,
+			},
+			Flags: OutputFlags{OutputAssertions: "ambivalent", Match: "regex"},
+		}
+		if err := options.Complete(); err != nil {
+			t.Errorf("%s: unexpected error completing options: %v", testCase.name, err)
+			continue
+		}
+		err := options.Validate()
+		if expected, actual := testCase.expectedError, err != nil; expected != actual {
+			t.Errorf("%s: expected error %v, got %v", testCase.name, expected, err)
+		}
+	}
+}
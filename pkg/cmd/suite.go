@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+	"github.com/stevekuznetsov/exec-assert/pkg/suite"
+	"github.com/stevekuznetsov/exec-assert/pkg/summarizer"
+)
+
+// SuiteOptions is able to run every test in suite files and tally the results
+type SuiteOptions struct {
+	// Paths are the paths to the suite files
+	Paths []string
+
+	// Defaults is the configuration that tests in the suites take any field they do not set from
+	Defaults api.ExecutionAssertionConfig
+
+	// UpdateGoldenFiles determines if the golden files of every test should be rewritten with the output instead
+	UpdateGoldenFiles bool
+
+	// Format is the format in which every test and the tally are reported; reports in JSON make up JSON Lines
+	Format string
+
+	// JUnitPath is the path to a JUnit XML report to record every test in, if any
+	JUnitPath string
+
+	// Color determines when text reports of every test and the tally are rendered in color
+	Color string
+
+	// Output is the writer to which output should go
+	Output io.Writer
+
+	// suites are the suites loaded from the suite files
+	suites []*suite.Suite
+
+	// colorMode determines when text reports are rendered in color
+	colorMode api.ColorMode
+}
+
+// Complete loads the suite files
+func (o *SuiteOptions) Complete() error {
+	switch o.Format {
+	case "", api.ReportFormatText, api.ReportFormatJSON, api.ReportFormatTAP:
+	default:
+		return fmt.Errorf("unrecognized format: got %q, expected one of %s", o.Format, api.ValidReportFormats)
+	}
+
+	colorMode, err := parseColorMode(o.Color)
+	if err != nil {
+		return err
+	}
+	o.colorMode = colorMode
+
+	for _, path := range o.Paths {
+		loadedSuite, err := suite.Load(path)
+		if err != nil {
+			return err
+		}
+		o.suites = append(o.suites, loadedSuite)
+	}
+	return nil
+}
+
+// Validate validates that there are tests to run
+func (o *SuiteOptions) Validate() error {
+	for _, loadedSuite := range o.suites {
+		if len(loadedSuite.Tests) > 0 {
+			return nil
+		}
+	}
+	return errors.New("suites must contain at least one test")
+}
+
+// Run runs every test in the suites, continuing past failures, then prints a tally of the tests that passed and failed.
+// Once the context is done, the running test is killed and no more tests are run.
+func (o *SuiteOptions) Run(ctx context.Context) (bool, error) {
+	if o.Format == api.ReportFormatTAP {
+		planned := 0
+		for _, loadedSuite := range o.suites {
+			planned += len(loadedSuite.Tests)
+		}
+		fmt.Fprintf(o.Output, "TAP version 13\n1..%d\n", planned)
+	}
+
+	var passed, total int
+	var failures []string
+	for _, loadedSuite := range o.suites {
+		for _, test := range loadedSuite.Tests {
+			if ctx.Err() != nil {
+				return false, fmt.Errorf("suite interrupted after %d of its tests: %v", total, ctx.Err())
+			}
+			total++
+			name := test.Name
+			if len(name) == 0 {
+				name = fmt.Sprintf("test %d (%#q)", total, test.Command)
+			}
+
+			success, err := o.runTest(ctx, test, total, name)
+			if err != nil {
+				switch o.Format {
+				case api.ReportFormatJSON:
+					report, formatErr := summarizer.FormatJSON(api.Report{SchemaVersion: api.ReportSchemaVersion, Kind: api.ReportKindTest, Name: name, Error: err.Error()})
+					if formatErr != nil {
+						return false, formatErr
+					}
+					fmt.Fprint(o.Output, report)
+				case api.ReportFormatTAP:
+					fmt.Fprint(o.Output, summarizer.FormatTAPError(total, name, err))
+				default:
+					fmt.Fprintf(o.Output, "Error running %s: %v\n", name, err)
+				}
+			}
+			if success {
+				passed++
+			} else {
+				failures = append(failures, name)
+			}
+		}
+	}
+
+	if o.Format == api.ReportFormatJSON {
+		if failures == nil {
+			failures = []string{}
+		}
+		report, err := summarizer.FormatJSON(api.SuiteReport{SchemaVersion: api.ReportSchemaVersion, Kind: api.ReportKindSuite, Passed: len(failures) == 0, Tests: total, PassedTests: passed, FailedTests: failures})
+		if err != nil {
+			return false, err
+		}
+		fmt.Fprint(o.Output, report)
+		return len(failures) == 0, nil
+	}
+
+	// a TAP harness reads the tally from the test lines, so it is only written as a comment, which is never colored
+	renderer := summarizer.Renderer{Color: useColor(o.colorMode, o.Output)}
+	if o.Format == api.ReportFormatTAP {
+		fmt.Fprint(o.Output, "# ")
+		renderer.Color = false
+	}
+	fmt.Fprint(o.Output, renderer.Tally(passed, total, failures))
+	return len(failures) == 0, nil
+}
+
+// runTest runs one test from a suite the same way that a test configured with flags is run
+func (o *SuiteOptions) runTest(ctx context.Context, test suite.Test, number int, name string) (bool, error) {
+	config, err := test.Config(o.Defaults)
+	if err != nil {
+		return false, o.recordError(name, fmt.Errorf("failed to configure test: %v", err))
+	}
+	// every report names the test the same way, even when the suite did not
+	config.Name = name
+	for i := range config.Assertions {
+		if config.Assertions[i].Kind == api.OutputAssertionGolden {
+			config.Assertions[i].Options.Update = o.UpdateGoldenFiles
+		}
+	}
+
+	options := ExecuteAssertOptions{
+		Config:     config,
+		Format:     o.Format,
+		TestNumber: number,
+		JUnitPath:  o.JUnitPath,
+		Color:      o.Color,
+		Output:     o.Output,
+	}
+
+	if err := options.Complete(); err != nil {
+		return false, o.recordError(name, fmt.Errorf("failed to configure test: %v", err))
+	}
+
+	if err := options.Validate(); err != nil {
+		return false, o.recordError(name, fmt.Errorf("failed to validate configuration: %v", err))
+	}
+
+	// a test that fails to run records itself in the JUnit XML report
+	return options.Run(ctx)
+}
+
+// recordError records a test that could not be run as failed in the JUnit XML report, if any, and returns the error
+func (o *SuiteOptions) recordError(name string, err error) error {
+	if len(o.JUnitPath) > 0 {
+		testCase := summarizer.JUnitTestCase{Name: name, ClassName: summarizer.JUnitSuiteName, Time: "0.000", Failure: &summarizer.JUnitFailure{Message: err.Error()}}
+		if err := summarizer.AppendJUnitTestCase(o.JUnitPath, testCase); err != nil {
+			return err
+		}
+	}
+	return err
+}
@@ -0,0 +1,72 @@
+package command
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+	"github.com/stevekuznetsov/exec-assert/pkg/output"
+	"github.com/stevekuznetsov/exec-assert/pkg/result"
+)
+
+// NewBackoffExecutor returns a new Executor that executes the command until the assertions are met by the given number
+// of consecutive executions, waiting longer after each execution, and returns its results and output
+func NewBackoffExecutor(command string, resultTester result.Tester, outputTesters []output.Tester, timeout, initialInterval, maxInterval time.Duration, multiplier, jitter float64, attemptTimeout, gracePeriod time.Duration, consecutive int) Executor {
+	return &backoffExecutor{
+		untilExecutor: untilExecutor{
+			command:        command,
+			resultTester:   resultTester,
+			outputTesters:  outputTesters,
+			timeout:        timeout,
+			interval:       initialInterval,
+			attemptTimeout: attemptTimeout,
+			gracePeriod:    gracePeriod,
+			consecutive:    consecutive,
+		},
+		maxInterval: maxInterval,
+		multiplier:  multiplier,
+		jitter:      jitter,
+		random:      rand.Float64,
+	}
+}
+
+// backoffExecutor executes the command until the assertions are met, waiting longer after each execution, and
+// returns its results and output
+type backoffExecutor struct {
+	// untilExecutor executes the command, waiting for its interval after the first execution
+	untilExecutor
+
+	// maxInterval is the longest the executor waits before re-trying a command execution
+	maxInterval time.Duration
+
+	// multiplier is the factor by which the interval grows after each execution
+	multiplier float64
+
+	// jitter is the fraction of each interval by which it is randomly lengthened or shortened
+	jitter float64
+
+	// random returns a random number in [0.0,1.0) to draw the jitter with
+	random func() float64
+}
+
+// Execute executes the command using `bash -c` until the assertions are met and returns the result and the record
+// of every execution, each holding its output and the verdicts of the assertions on it
+func (e *backoffExecutor) Execute(ctx context.Context) (time.Duration, error, []api.Attempt, error) {
+	return e.execute(ctx, repetition{
+		interval: e.backoff,
+		done:     e.streak(),
+	})
+}
+
+// backoff determines how long to wait after the execution with the given index, growing the interval by the
+// multiplier after each execution until it reaches the maximum interval
+func (e *backoffExecutor) backoff(attempt int) time.Duration {
+	interval := math.Min(float64(e.interval)*math.Pow(e.multiplier, float64(attempt)), float64(e.maxInterval))
+	if e.jitter > 0 {
+		// spread intervals evenly on either side of the schedule, so that they are not any longer on average
+		interval += interval * e.jitter * (2*e.random() - 1)
+	}
+	return time.Duration(interval)
+}
@@ -0,0 +1,54 @@
+package command
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	testCases := []struct {
+		name             string
+		multiplier       float64
+		jitter           float64
+		random           float64
+		expectedBackoffs []time.Duration
+	}{
+		{
+			name:             "doubling up to the maximum",
+			multiplier:       2,
+			expectedBackoffs: []time.Duration{200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, 1600 * time.Millisecond, 3200 * time.Millisecond, 5 * time.Second, 5 * time.Second},
+		},
+		{
+			name:             "constant",
+			multiplier:       1,
+			expectedBackoffs: []time.Duration{200 * time.Millisecond, 200 * time.Millisecond, 200 * time.Millisecond},
+		},
+		{
+			name:             "shortest jitter",
+			multiplier:       2,
+			jitter:           0.5,
+			random:           0,
+			expectedBackoffs: []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, 1600 * time.Millisecond, 2500 * time.Millisecond},
+		},
+		{
+			name:             "jitter in the middle",
+			multiplier:       2,
+			jitter:           0.5,
+			random:           0.5,
+			expectedBackoffs: []time.Duration{200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, 1600 * time.Millisecond, 3200 * time.Millisecond, 5 * time.Second},
+		},
+	}
+
+	for _, testCase := range testCases {
+		executor := NewBackoffExecutor("true", nil, nil, time.Minute, 200*time.Millisecond, 5*time.Second, testCase.multiplier, testCase.jitter, 0, 0, 1).(*backoffExecutor)
+		executor.random = func() float64 { return testCase.random }
+		for i, expected := range testCase.expectedBackoffs {
+			if actual := executor.backoff(i); expected != actual {
+				t.Errorf("%s: expected to wait %v after attempt %d, got %v", testCase.name, expected, i+1, actual)
+			}
+		}
+		if expected, actual := testCase.expectedBackoffs[len(testCase.expectedBackoffs)-1], executor.backoff(2000); expected != actual {
+			t.Errorf("%s: expected to wait %v after many attempts, got %v", testCase.name, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package command
+
+import (
+	"bytes"
+	"sync"
+	"time"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+)
+
+// newOutputCapture returns a new outputCapture that timestamps lines relative to the given start time
+func newOutputCapture(startTime time.Time) *outputCapture {
+	return &outputCapture{startTime: startTime}
+}
+
+// outputCapture records the lines written to both of the command's streams in the order they were written
+type outputCapture struct {
+	// startTime is the time against which line offsets are measured
+	startTime time.Time
+
+	// lock guards lines, as both streams are read concurrently
+	lock sync.Mutex
+
+	// lines holds every line written by the command, across both streams, in the order they were written
+	lines []api.OutputLine
+}
+
+// record records a line written to a stream
+func (c *outputCapture) record(stream api.OutputStream, line string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.lines = append(c.lines, api.OutputLine{
+		Stream: stream,
+		Offset: time.Since(c.startTime),
+		Text:   line,
+	})
+}
+
+// Lines returns the lines written by the command
+func (c *outputCapture) Lines() []api.OutputLine {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.lines
+}
+
+// newStreamWriter returns a new streamWriter that records output to the given stream in the capture
+func newStreamWriter(capture *outputCapture, stream api.OutputStream) *streamWriter {
+	return &streamWriter{capture: capture, stream: stream}
+}
+
+// streamWriter buffers everything written to one stream, recording each complete line in the capture as it is written
+type streamWriter struct {
+	capture *outputCapture
+	stream  api.OutputStream
+
+	// buffer holds everything written to the stream
+	buffer bytes.Buffer
+
+	// partial holds the last line written to the stream until it is terminated
+	partial []byte
+}
+
+// Write buffers output and records any lines it completes
+func (w *streamWriter) Write(p []byte) (int, error) {
+	w.buffer.Write(p)
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		w.capture.record(w.stream, string(w.partial[:i]))
+		w.partial = w.partial[i+1:]
+	}
+	return len(p), nil
+}
+
+// Close records the last line written to the stream if it was not terminated
+func (w *streamWriter) Close() error {
+	if len(w.partial) > 0 {
+		w.capture.record(w.stream, string(w.partial))
+		w.partial = nil
+	}
+	return nil
+}
+
+// String returns everything written to the stream
+func (w *streamWriter) String() string {
+	return w.buffer.String()
+}
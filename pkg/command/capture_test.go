@@ -0,0 +1,90 @@
+package command
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+)
+
+func TestOutputCapture(t *testing.T) {
+	testCases := []struct {
+		name           string
+		writes         []struct{ stream, content string }
+		expectedLines  []api.OutputLine
+		expectedStdout string
+		expectedStderr string
+	}{
+		{
+			name:           "no output",
+			expectedStdout: "",
+			expectedStderr: "",
+		},
+		{
+			name: "lines interleaved across streams",
+			writes: []struct{ stream, content string }{
+				{stream: api.OutputStreamStdout, content: "first\n"},
+				{stream: api.OutputStreamStderr, content: "second\n"},
+				{stream: api.OutputStreamStdout, content: "third\n"},
+			},
+			expectedLines: []api.OutputLine{
+				{Stream: api.OutputStreamStdout, Text: "first"},
+				{Stream: api.OutputStreamStderr, Text: "second"},
+				{Stream: api.OutputStreamStdout, Text: "third"},
+			},
+			expectedStdout: "first\nthird\n",
+			expectedStderr: "second\n",
+		},
+		{
+			name: "line split across writes",
+			writes: []struct{ stream, content string }{
+				{stream: api.OutputStreamStdout, content: "fir"},
+				{stream: api.OutputStreamStderr, content: "second\n"},
+				{stream: api.OutputStreamStdout, content: "st\nthi"},
+			},
+			expectedLines: []api.OutputLine{
+				{Stream: api.OutputStreamStderr, Text: "second"},
+				{Stream: api.OutputStreamStdout, Text: "first"},
+				{Stream: api.OutputStreamStdout, Text: "thi"},
+			},
+			expectedStdout: "first\nthi",
+			expectedStderr: "second\n",
+		},
+	}
+
+	for _, testCase := range testCases {
+		capture := newOutputCapture(time.Now())
+		writers := map[string]*streamWriter{
+			api.OutputStreamStdout: newStreamWriter(capture, api.OutputStreamStdout),
+			api.OutputStreamStderr: newStreamWriter(capture, api.OutputStreamStderr),
+		}
+		for _, write := range testCase.writes {
+			writers[write.stream].Write([]byte(write.content))
+		}
+		for _, writer := range []*streamWriter{writers[api.OutputStreamStdout], writers[api.OutputStreamStderr]} {
+			writer.Close()
+		}
+
+		lines := capture.Lines()
+		for i := range lines {
+			// offsets depend on timing, so we only check that they are ordered
+			if i > 0 && lines[i].Offset < lines[i-1].Offset {
+				t.Errorf("%s: output capture recorded line %d before line %d", testCase.name, i, i-1)
+			}
+			lines[i].Offset = 0
+		}
+
+		if expected, actual := testCase.expectedLines, lines; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("%s: output capture did not record correct lines: expected %v, got %v", testCase.name, expected, actual)
+		}
+
+		if expected, actual := testCase.expectedStdout, writers[api.OutputStreamStdout].String(); expected != actual {
+			t.Errorf("%s: output capture did not buffer correct stdout: expected %q, got %q", testCase.name, expected, actual)
+		}
+
+		if expected, actual := testCase.expectedStderr, writers[api.OutputStreamStderr].String(); expected != actual {
+			t.Errorf("%s: output capture did not buffer correct stderr: expected %q, got %q", testCase.name, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package command
+
+import (
+	"context"
+	"time"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+	"github.com/stevekuznetsov/exec-assert/pkg/output"
+	"github.com/stevekuznetsov/exec-assert/pkg/result"
+)
+
+// NewConsistentlyExecutor returns a new Executor that executes the command until the timeout passes or the assertions
+// are broken, and returns its results and output
+func NewConsistentlyExecutor(command string, resultTester result.Tester, outputTesters []output.Tester, timeout, interval, attemptTimeout, gracePeriod time.Duration) Executor {
+	return &consistentlyExecutor{
+		untilExecutor: untilExecutor{
+			command:        command,
+			resultTester:   resultTester,
+			outputTesters:  outputTesters,
+			timeout:        timeout,
+			interval:       interval,
+			attemptTimeout: attemptTimeout,
+			gracePeriod:    gracePeriod,
+		},
+	}
+}
+
+// consistentlyExecutor executes the command until the timeout passes or the assertions are broken, and returns its
+// results and output
+type consistentlyExecutor struct {
+	// untilExecutor executes the command and tests the assertions on each execution
+	untilExecutor
+}
+
+// Execute executes the command using `bash -c` every interval until the timeout passes, stopping at the first execution
+// that breaks the assertions, and returns the result and the record of every execution, the last of which broke the
+// assertions if any did
+func (e *consistentlyExecutor) Execute(ctx context.Context) (time.Duration, error, []api.Attempt, error) {
+	return e.execute(ctx, repetition{
+		interval: func(int) time.Duration { return e.interval },
+		done:     func(passed bool) bool { return !passed },
+		// if the assertions held until the timeout passed, they held for the whole time
+		discardCutShort: true,
+	})
+}
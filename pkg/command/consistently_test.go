@@ -0,0 +1,83 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/output"
+	"github.com/stevekuznetsov/exec-assert/pkg/result"
+	"github.com/stevekuznetsov/exec-assert/pkg/util"
+)
+
+func TestConsistentlyExecutor(t *testing.T) {
+	outputTesters, err := output.NewTesters(nil)
+	if err != nil {
+		t.Fatalf("failed to create output testers: %v", err)
+	}
+
+	counter := filepath.Join(t.TempDir(), "attempts")
+	testCases := []struct {
+		name           string
+		command        string
+		minAttempts    int
+		maxAttempts    int
+		expectedBroken bool
+	}{
+		{
+			name:           "assertions hold every time",
+			command:        "exit 0",
+			minAttempts:    2,
+			maxAttempts:    10,
+			expectedBroken: false,
+		},
+		{
+			name:           "assertions broken on the first attempt",
+			command:        "exit 1",
+			minAttempts:    1,
+			maxAttempts:    1,
+			expectedBroken: true,
+		},
+		{
+			name:           "assertions broken on the third attempt",
+			command:        fmt.Sprintf("echo >> %[1]s; [[ $( wc -l < %[1]s ) -lt 3 ]]", counter),
+			minAttempts:    3,
+			maxAttempts:    3,
+			expectedBroken: true,
+		},
+		{
+			name:           "execution cut short by the timeout",
+			command:        "sleep 0.15",
+			minAttempts:    2,
+			maxAttempts:    4,
+			expectedBroken: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		executor := NewConsistentlyExecutor(testCase.command, result.NewSuccessTester(), outputTesters, 500*time.Millisecond, 100*time.Millisecond, 0, time.Second)
+		_, compoundResult, attempts, err := executor.Execute(context.Background())
+		if err != nil {
+			t.Errorf("%s: failed to execute: %v", testCase.name, err)
+			continue
+		}
+
+		if len(attempts) < testCase.minAttempts || len(attempts) > testCase.maxAttempts {
+			t.Errorf("%s: expected between %d and %d attempts, got %d", testCase.name, testCase.minAttempts, testCase.maxAttempts, len(attempts))
+			continue
+		}
+		if expected, actual := len(attempts), len(compoundResult.(*util.CompoundResult).Results); expected != actual {
+			t.Errorf("%s: expected a result for each of %d attempts, got %d", testCase.name, expected, actual)
+		}
+		for i, attempt := range attempts[:len(attempts)-1] {
+			if !attempt.ResultVerdict.Passed {
+				t.Errorf("%s: attempt %d broke the assertions before the last attempt", testCase.name, i+1)
+			}
+		}
+		if expected, actual := testCase.expectedBroken, !attempts[len(attempts)-1].ResultVerdict.Passed; expected != actual {
+			t.Errorf("%s: expected the last attempt to break the assertions: %v, got %v", testCase.name, expected, actual)
+		}
+	}
+}
@@ -1,11 +1,17 @@
 package command
 
-import "time"
+import (
+	"context"
+	"time"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+)
 
 // Executor knows how to execute a command, returning the results of execution
 type Executor interface {
-	// Execute executes a command using the Executor's strategy, returning the execution
-	// duration in milliseconds, the result of the execution and the messages logged to
-	// stdout and stderr.
-	Execute() (duration time.Duration, result error, stdout, stderr string, err error)
+	// Execute executes a command using the Executor's strategy, returning the duration of the
+	// whole execution, its result, and the record of every execution of the command in order,
+	// each holding its own result and output. Any command still running when the context is
+	// done is killed.
+	Execute(ctx context.Context) (duration time.Duration, result error, attempts []api.Attempt, err error)
 }
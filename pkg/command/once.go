@@ -1,58 +1,147 @@
 package command
 
 import (
-	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"os/exec"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+	"github.com/stevekuznetsov/exec-assert/pkg/util"
 )
 
 // NewOnceExecutor returns a new Executor that executes the command once and returns the execution duration, its results and output
-func NewOnceExecutor(command string) Executor {
-	return &onceExecutor{command: command}
+func NewOnceExecutor(command string, attemptTimeout, gracePeriod time.Duration) Executor {
+	return &onceExecutor{
+		command:        command,
+		attemptTimeout: attemptTimeout,
+		gracePeriod:    gracePeriod,
+	}
 }
 
 // onceExecutor executes the command once and returns the execution duration, its results and output
 type onceExecutor struct {
 	command string
+
+	// attemptTimeout is how long the command may run before it is killed, if non-zero
+	attemptTimeout time.Duration
+
+	// gracePeriod is how long the command has to exit after SIGTERM before it is sent SIGKILL
+	gracePeriod time.Duration
 }
 
-// Execute executes the command using `bash -c` and returns the execution duration, result and output
-func (e *onceExecutor) Execute() (time.Duration, error, string, string, error) {
+// Execute executes the command using `bash -c` and returns the execution duration, result and the record of
+// the one execution, which holds the output
+func (e *onceExecutor) Execute(ctx context.Context) (time.Duration, error, []api.Attempt, error) {
+	if e.attemptTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.attemptTimeout)
+		defer cancel()
+	}
+
 	command := exec.Command("bash", "-c", e.command)
+	// the command runs in its own process group so that we can signal any children it spawns along with it
+	command.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
 	stdoutPipe, err := command.StdoutPipe()
 	if err != nil {
-		return 0, nil, "", "", fmt.Errorf("failed to attach to stdout pipe: %v", err)
+		return 0, nil, nil, fmt.Errorf("failed to attach to stdout pipe: %v", err)
 	}
 
 	stderrPipe, err := command.StderrPipe()
 	if err != nil {
-		return 0, nil, "", "", fmt.Errorf("failed to attach to stderr pipe: %v", err)
+		return 0, nil, nil, fmt.Errorf("failed to attach to stderr pipe: %v", err)
 	}
 
 	startTime := time.Now()
 
 	if err = command.Start(); err != nil {
-		return 0, nil, "", "", fmt.Errorf("failed to start command execution: %v", err)
+		return 0, nil, nil, fmt.Errorf("failed to start command execution: %v", err)
 	}
 
-	var stdoutBuffer bytes.Buffer
-	_, err = stdoutBuffer.ReadFrom(stdoutPipe)
-	if err != nil {
-		return 0, nil, "", "", fmt.Errorf("failed to read from stdout: %v", err)
+	exited := make(chan struct{})
+	killed := make(chan time.Duration, 1)
+	go e.killOnDeadline(ctx, command.Process.Pid, startTime, exited, killed)
+
+	// both streams must be read at once, as a command that fills the pipe buffer for one stream will block until
+	// it is read, so reading the streams one at a time can deadlock
+	capture := newOutputCapture(startTime)
+	stdoutWriter := newStreamWriter(capture, api.OutputStreamStdout)
+	stderrWriter := newStreamWriter(capture, api.OutputStreamStderr)
+	var stdoutErr, stderrErr error
+	var reads sync.WaitGroup
+	reads.Add(2)
+	go func() {
+		defer reads.Done()
+		_, stdoutErr = io.Copy(stdoutWriter, stdoutPipe)
+	}()
+	go func() {
+		defer reads.Done()
+		_, stderrErr = io.Copy(stderrWriter, stderrPipe)
+	}()
+	reads.Wait()
+	stdoutWriter.Close()
+	stderrWriter.Close()
+
+	result := command.Wait()
+	close(exited)
+	if after, ok := <-killed; ok && ctx.Err() == context.DeadlineExceeded {
+		// a command killed because exec-assert was interrupted did not time out, so it is reported as killed by the signal
+		result = util.NewTimeoutResult(after, result)
 	}
 
-	var stderrBuffer bytes.Buffer
-	_, err = stderrBuffer.ReadFrom(stderrPipe)
-	if err != nil {
-		return 0, nil, "", "", fmt.Errorf("failed to read from stderr: %v", err)
+	if stdoutErr != nil {
+		return 0, nil, nil, fmt.Errorf("failed to read from stdout: %v", stdoutErr)
 	}
 
-	result := command.Wait()
-	// we don't want captured output to have a trailing newline for formatting reasons
-	stdout := strings.TrimRight(stdoutBuffer.String(), "\n")
-	stderr := strings.TrimRight(stderrBuffer.String(), "\n")
+	if stderrErr != nil {
+		return 0, nil, nil, fmt.Errorf("failed to read from stderr: %v", stderrErr)
+	}
+
+	duration := time.Since(startTime)
+	attempt := api.Attempt{
+		Duration: duration,
+		Result:   result,
+		// we don't want captured output to have a trailing newline for formatting reasons
+		Stdout: strings.TrimRight(stdoutWriter.String(), "\n"),
+		Stderr: strings.TrimRight(stderrWriter.String(), "\n"),
+		// golden files are compared to the output exactly as it was written
+		RawStdout: stdoutWriter.String(),
+		RawStderr: stderrWriter.String(),
+		Combined:  capture.Lines(),
+	}
+
+	return duration, result, []api.Attempt{attempt}, nil
+}
+
+// killOnDeadline waits for the command to exit or for the context to be done, whichever happens first. If the
+// context is done first, the process group is sent SIGTERM and, if it has not exited after the grace period,
+// SIGKILL. The time at which the deadline was reached is sent on killed if the command was signalled, and killed
+// is closed once the command has exited.
+func (e *onceExecutor) killOnDeadline(ctx context.Context, pid int, startTime time.Time, exited <-chan struct{}, killed chan<- time.Duration) {
+	defer close(killed)
 
-	return time.Since(startTime), result, stdout, stderr, nil
+	select {
+	case <-exited:
+		return
+	case <-ctx.Done():
+	}
+
+	// signalling the negative pid signals every process in the group
+	if err := syscall.Kill(-pid, syscall.SIGTERM); err != nil {
+		// the process group is already gone, so the command exited on its own
+		<-exited
+		return
+	}
+	killed <- time.Since(startTime)
+
+	select {
+	case <-exited:
+	case <-time.After(e.gracePeriod):
+		syscall.Kill(-pid, syscall.SIGKILL)
+		<-exited
+	}
 }
@@ -1,23 +1,28 @@
 package command
 
 import (
+	"context"
 	"fmt"
-	"strings"
 	"time"
 
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
 	"github.com/stevekuznetsov/exec-assert/pkg/output"
 	"github.com/stevekuznetsov/exec-assert/pkg/result"
 	"github.com/stevekuznetsov/exec-assert/pkg/util"
 )
 
-// NewUntilExecutor returns a new Executor that executes the command until the assertions are met and returns its results and output
-func NewUntilExecutor(command string, resultTester result.Tester, outputTesters []output.Tester, timeout, interval time.Duration) Executor {
+// NewUntilExecutor returns a new Executor that executes the command until the assertions are met by the given number of
+// consecutive executions and returns its results and output
+func NewUntilExecutor(command string, resultTester result.Tester, outputTesters []output.Tester, timeout, interval, attemptTimeout, gracePeriod time.Duration, consecutive int) Executor {
 	return &untilExecutor{
-		command:       command,
-		resultTester:  resultTester,
-		outputTesters: outputTesters,
-		timeout:       timeout,
-		interval:      interval,
+		command:        command,
+		resultTester:   resultTester,
+		outputTesters:  outputTesters,
+		timeout:        timeout,
+		interval:       interval,
+		attemptTimeout: attemptTimeout,
+		gracePeriod:    gracePeriod,
+		consecutive:    consecutive,
 	}
 }
 
@@ -36,50 +41,118 @@ type untilExecutor struct {
 
 	// interval is how long the executor waits before re-trying a command execution
 	interval time.Duration
+
+	// attemptTimeout is how long any one execution of the command may run before it is killed, if non-zero
+	attemptTimeout time.Duration
+
+	// gracePeriod is how long a command has to exit after SIGTERM before it is sent SIGKILL
+	gracePeriod time.Duration
+
+	// consecutive is how many back-to-back executions must meet the assertions before the executor stops; one
+	// execution is enough if it is not set
+	consecutive int
+}
+
+// Execute executes the command using `bash -c` until the assertions are met and returns the result and the record
+// of every execution, each holding its output and the verdicts of the assertions on it
+func (e *untilExecutor) Execute(ctx context.Context) (time.Duration, error, []api.Attempt, error) {
+	return e.execute(ctx, repetition{
+		interval: func(int) time.Duration { return e.interval },
+		done:     e.streak(),
+	})
+}
+
+// repetition determines how long the executor waits between executions of the command and when it stops
+type repetition struct {
+	// interval determines how long to wait after the execution with the given index
+	interval func(attempt int) time.Duration
+
+	// done determines whether to stop after an execution that did or did not meet the assertions
+	done func(passed bool) bool
+
+	// discardCutShort discards an execution after the first that the timeout cut short, as it ran out of time
+	// rather than breaking the assertions
+	discardCutShort bool
 }
 
-// Execute executes the command using `bash -c` until the assertions are met and returns the result and output
-func (e *untilExecutor) Execute() (time.Duration, error, string, string, error) {
+// streak returns a predicate that is done once enough back-to-back executions have met the assertions
+func (e *untilExecutor) streak() func(passed bool) bool {
+	var streak int
+	return func(passed bool) bool {
+		if !passed {
+			// a streak of executions that met the assertions has to start over after any execution that did not
+			streak = 0
+			return false
+		}
+		streak++
+		return streak >= e.consecutive
+	}
+}
+
+// execute executes the command until the repetition is done or the timeout passes
+func (e *untilExecutor) execute(ctx context.Context, repetition repetition) (time.Duration, error, []api.Attempt, error) {
 	var results []error
-	var stdouts, stderrs []string
+	var attempts []api.Attempt
 	startTime := time.Now()
 
+	if e.timeout > 0 {
+		// an execution still running when we time out is killed, so we cannot block past the timeout
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.timeout)
+		defer cancel()
+	}
+
 	for {
-		_, result, stdout, stderr, err := NewOnceExecutor(e.command).Execute()
+		attempt, passed, err := e.attempt(ctx, startTime)
 		if err != nil {
-			return 0, nil, "", "", fmt.Errorf("error executing command: %v", err)
+			return 0, nil, nil, err
 		}
-		results = append(results, result)
-
-		if len(stdout) > 0 {
-			stdouts = append(stdouts, stdout)
+		if repetition.discardCutShort && ctx.Err() != nil && util.IsTimeoutResult(attempt.Result) && len(attempts) > 0 {
+			// the timeout passed while this execution was running, so it was cut short
+			break
 		}
+		results = append(results, attempt.Result)
+		attempts = append(attempts, attempt)
 
-		if len(stderr) > 0 {
-			stderrs = append(stderrs, stderr)
+		if repetition.done(passed) {
+			break
 		}
-
-		resultTestSuccess := e.resultTester.Test(result)
-		outputTestSuccess := true
-		for _, tester := range e.outputTesters {
-			// all testers need to succeed to succeed overall
-			outputTestSuccess = outputTestSuccess && tester.Test(stdout, stderr)
+		if time.Since(startTime) > e.timeout || ctx.Err() != nil {
+			// we check timeout after command execution so that we may have one last execution before we're done
+			break
 		}
 
-		if resultTestSuccess && outputTestSuccess {
-			break
+		select {
+		case <-time.After(repetition.interval(len(attempts) - 1)):
+		case <-ctx.Done():
 		}
-		if time.Since(startTime) > e.timeout {
-			// we check timeout after command execution so that we may have one last execution before we're done
+		if ctx.Err() != nil {
+			// the timeout passed while we were waiting, so any further execution would be killed immediately
 			break
 		}
-		time.Sleep(e.interval)
 	}
 
-	duration := time.Since(startTime)
-	result := util.NewCompoundResult(results)
-	stdout := strings.Join(stdouts, util.RecordSeparator)
-	stderr := strings.Join(stderrs, util.RecordSeparator)
+	return time.Since(startTime), util.NewCompoundResult(results), attempts, nil
+}
+
+// attempt executes the command once, recording when it started relative to the start of the whole execution, and
+// returns the record of the execution and whether it met the assertions
+func (e *untilExecutor) attempt(ctx context.Context, startTime time.Time) (api.Attempt, bool, error) {
+	attemptStart := time.Since(startTime)
+	_, result, executed, err := NewOnceExecutor(e.command, e.attemptTimeout, e.gracePeriod).Execute(ctx)
+	if err != nil {
+		return api.Attempt{}, false, fmt.Errorf("error executing command: %v", err)
+	}
 
-	return duration, result, stdout, stderr, nil
+	attempt := executed[0]
+	attempt.Start = attemptStart
+	attempt.ResultVerdict = e.resultTester.Test(result)
+	outputTestSuccess := true
+	for _, tester := range e.outputTesters {
+		// all testers need to succeed to succeed overall
+		verdict := output.TestAttempt(tester, attempt)
+		attempt.OutputVerdicts = append(attempt.OutputVerdicts, verdict)
+		outputTestSuccess = outputTestSuccess && verdict.Passed
+	}
+	return attempt, attempt.ResultVerdict.Passed && outputTestSuccess, nil
 }
@@ -0,0 +1,118 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/output"
+	"github.com/stevekuznetsov/exec-assert/pkg/result"
+	"github.com/stevekuznetsov/exec-assert/pkg/util"
+)
+
+func TestUntilExecutorAttempts(t *testing.T) {
+	outputTesters, err := output.NewTesters(nil)
+	if err != nil {
+		t.Fatalf("failed to create output testers: %v", err)
+	}
+
+	testCases := []struct {
+		name             string
+		command          string
+		consecutive      int
+		minAttempts      int
+		maxAttempts      int
+		expectedCode     int
+		expectedVerdicts bool
+		expectedStdout   string
+	}{
+		{
+			name:             "assertions met on the first attempt",
+			command:          "exit 0",
+			minAttempts:      1,
+			maxAttempts:      1,
+			expectedCode:     0,
+			expectedVerdicts: true,
+		},
+		{
+			name:             "assertions met by three attempts in a row",
+			command:          "exit 0",
+			consecutive:      3,
+			minAttempts:      3,
+			maxAttempts:      3,
+			expectedCode:     0,
+			expectedVerdicts: true,
+		},
+		{
+			name:             "assertions never met",
+			command:          "exit 7",
+			minAttempts:      2,
+			maxAttempts:      10,
+			expectedCode:     7,
+			expectedVerdicts: false,
+		},
+		{
+			name:             "output holding the record separator character",
+			command:          `printf 'first\x1esecond'; exit 3`,
+			minAttempts:      2,
+			maxAttempts:      10,
+			expectedCode:     3,
+			expectedVerdicts: false,
+			expectedStdout:   "first\x1esecond",
+		},
+	}
+
+	for _, testCase := range testCases {
+		executor := NewUntilExecutor(testCase.command, result.NewSuccessTester(), outputTesters, 500*time.Millisecond, 100*time.Millisecond, 0, time.Second, testCase.consecutive)
+		_, _, attempts, err := executor.Execute(context.Background())
+		if err != nil {
+			t.Errorf("%s: failed to execute: %v", testCase.name, err)
+			continue
+		}
+
+		if len(attempts) < testCase.minAttempts || len(attempts) > testCase.maxAttempts {
+			t.Errorf("%s: expected between %d and %d attempts, got %d", testCase.name, testCase.minAttempts, testCase.maxAttempts, len(attempts))
+		}
+		for i, attempt := range attempts {
+			if code, ok := util.ExitCode(attempt.Result); !ok || code != testCase.expectedCode {
+				t.Errorf("%s: attempt %d: expected exit code %d, got result %v", testCase.name, i+1, testCase.expectedCode, attempt.Result)
+			}
+			if expected, actual := testCase.expectedVerdicts, attempt.ResultVerdict.Passed; expected != actual {
+				t.Errorf("%s: attempt %d: expected result verdict %v, got %v", testCase.name, i+1, expected, actual)
+			}
+			if expected, actual := testCase.expectedStdout, attempt.Stdout; expected != actual {
+				t.Errorf("%s: attempt %d: expected stdout %q, got %q", testCase.name, i+1, expected, actual)
+			}
+			if i > 0 && attempt.Start < attempts[i-1].Start+attempts[i-1].Duration {
+				t.Errorf("%s: attempt %d started at %v, before attempt %d ended", testCase.name, i+1, attempt.Start, i)
+			}
+		}
+	}
+}
+
+func TestUntilExecutorStreakStartsOver(t *testing.T) {
+	outputTesters, err := output.NewTesters(nil)
+	if err != nil {
+		t.Fatalf("failed to create output testers: %v", err)
+	}
+
+	// the second execution fails, so the streak of two starts over and is only reached by the fourth
+	counter := filepath.Join(t.TempDir(), "attempts")
+	command := fmt.Sprintf("echo >> %[1]s; [[ $( wc -l < %[1]s ) -ne 2 ]]", counter)
+	executor := NewUntilExecutor(command, result.NewSuccessTester(), outputTesters, 5*time.Second, 10*time.Millisecond, 0, time.Second, 2)
+	_, _, attempts, err := executor.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("failed to execute: %v", err)
+	}
+
+	var verdicts []bool
+	for _, attempt := range attempts {
+		verdicts = append(verdicts, attempt.ResultVerdict.Passed)
+	}
+	if expected, actual := []bool{true, false, true, true}, verdicts; !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected attempts to meet the assertions like %v, got %v", expected, actual)
+	}
+}
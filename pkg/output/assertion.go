@@ -0,0 +1,100 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+)
+
+// NewTesters returns the Testers that make the given assertions about the output
+func NewTesters(assertions []api.Assertion) ([]Tester, error) {
+	testers := []Tester{}
+	for _, assertion := range assertions {
+		tester, err := NewTester(assertion)
+		if err != nil {
+			return nil, err
+		}
+		testers = append(testers, tester)
+	}
+	return testers, nil
+}
+
+// NewTester returns the Tester that makes the given assertion about the output
+func NewTester(assertion api.Assertion) (Tester, error) {
+	target, err := validateTarget(assertion.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	switch assertion.Kind {
+	case api.OutputAssertionAmbivalent:
+		return NewAmbivalentTester(), nil
+	case api.OutputAssertionContains, api.OutputAssertionExcludes, api.OutputAssertionMatchCount:
+		pattern, err := compileTest(assertion.Pattern, assertion.Options.Match)
+		if err != nil {
+			return nil, err
+		}
+		switch assertion.Kind {
+		case api.OutputAssertionContains:
+			return NewContainsTester(target, pattern), nil
+		case api.OutputAssertionExcludes:
+			return NewExcludesTester(target, pattern), nil
+		default:
+			return NewMatchCountTester(target, pattern, assertion.Options.Count), nil
+		}
+	case api.OutputAssertionLineCount:
+		return NewLineCountTester(target, assertion.Options.Count), nil
+	case api.OutputAssertionInOrder:
+		if len(assertion.Options.Sequence) == 0 {
+			return nil, errors.New("an in-order output assertion must have at least one test in its sequence")
+		}
+		var patterns []*regexp.Regexp
+		for _, test := range assertion.Options.Sequence {
+			pattern, err := compileTest(test, assertion.Options.Match)
+			if err != nil {
+				return nil, err
+			}
+			patterns = append(patterns, pattern)
+		}
+		return NewInOrderTester(target, patterns), nil
+	case api.OutputAssertionJSON:
+		query, err := ParseJSONQuery(assertion.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse JSON test: %v", err)
+		}
+		return NewJSONTester(query), nil
+	case api.OutputAssertionGolden:
+		if target == api.OutputTargetAny {
+			// golden files hold the output to one stream, so stdout is compared unless another is targeted
+			target = api.OutputTargetStdout
+		}
+		return NewGoldenTester(target, assertion.Pattern, assertion.Options.Update), nil
+	default:
+		return nil, fmt.Errorf("unrecognized output assertion: got %q, expected one of %s", assertion.Kind, api.ValidOutputAssertions)
+	}
+}
+
+// validateTarget validates the stream an assertion targets, which is any stream if it is not set
+func validateTarget(target api.OutputTarget) (api.OutputTarget, error) {
+	if len(target) == 0 {
+		return api.OutputTargetAny, nil
+	}
+	for _, validTarget := range api.ValidOutputTargets {
+		if target == validTarget {
+			return target, nil
+		}
+	}
+	return "", fmt.Errorf("unrecognized output target: got %q, expected one of %s", target, api.ValidOutputTargets)
+}
+
+// compileTest compiles a test with the match mode, which is a regular expression if it is not set
+func compileTest(test string, mode api.MatchMode) (*regexp.Regexp, error) {
+	switch mode {
+	case "", api.MatchModeLiteral, api.MatchModeGlob, api.MatchModeRegex:
+		return CompileTest(test, mode)
+	default:
+		return nil, fmt.Errorf("unrecognized match mode: got %q, expected one of %s", mode, api.ValidMatchModes)
+	}
+}
@@ -0,0 +1,77 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+)
+
+func TestNewTester(t *testing.T) {
+	testCases := []struct {
+		name           string
+		assertion      api.Assertion
+		stdout         string
+		stderr         string
+		expectedResult bool
+		expectedError  bool
+	}{
+		{
+			name:           "contains assertion matched as a regular expression by default",
+			assertion:      api.Assertion{Kind: api.OutputAssertionContains, Pattern: "a+"},
+			stdout:         "aaa",
+			expectedResult: true,
+		},
+		{
+			name:           "targeted excludes assertion matched literally",
+			assertion:      api.Assertion{Kind: api.OutputAssertionExcludes, Target: api.OutputTargetStderr, Pattern: "a+", Options: api.AssertionOptions{Match: api.MatchModeLiteral}},
+			stderr:         "a+",
+			expectedResult: false,
+		},
+		{
+			name:           "in-order assertion",
+			assertion:      api.Assertion{Kind: api.OutputAssertionInOrder, Target: api.OutputTargetStdout, Options: api.AssertionOptions{Sequence: []string{"a", "b"}}},
+			stdout:         "a\nb",
+			expectedResult: true,
+		},
+		{
+			name:           "JSON assertion",
+			assertion:      api.Assertion{Kind: api.OutputAssertionJSON, Pattern: ".a == 1"},
+			stdout:         `{"a": 1}`,
+			expectedResult: true,
+		},
+		{
+			name:          "in-order assertion without a sequence",
+			assertion:     api.Assertion{Kind: api.OutputAssertionInOrder},
+			expectedError: true,
+		},
+		{
+			name:          "invalid regular expression",
+			assertion:     api.Assertion{Kind: api.OutputAssertionContains, Pattern: "a("},
+			expectedError: true,
+		},
+		{
+			name:          "unrecognized target",
+			assertion:     api.Assertion{Kind: api.OutputAssertionContains, Target: "stdin"},
+			expectedError: true,
+		},
+		{
+			name:          "unrecognized kind",
+			assertion:     api.Assertion{Kind: "matches"},
+			expectedError: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		tester, err := NewTester(testCase.assertion)
+		if expected, actual := testCase.expectedError, err != nil; expected != actual {
+			t.Errorf("%s: expected error %v, got %v", testCase.name, expected, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if expected, actual := testCase.expectedResult, tester.Test(testCase.stdout, testCase.stderr, testCase.stdout+"\n"+testCase.stderr).Passed; expected != actual {
+			t.Errorf("%s: tester did not generate correct result: expected %v, got %v", testCase.name, expected, actual)
+		}
+	}
+}
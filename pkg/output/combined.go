@@ -0,0 +1,29 @@
+package output
+
+import (
+	"strings"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+)
+
+// CombinedText joins the lines written to both streams into the text that the user would have seen
+func CombinedText(lines []api.OutputLine) string {
+	texts := make([]string, 0, len(lines))
+	for _, line := range lines {
+		texts = append(texts, line.Text)
+	}
+	return strings.Join(texts, "\n")
+}
+
+// TestAttempt tests the output of one execution of the command, giving an ExactTester the output exactly as it was
+// written. Lines are only recorded in the combined output once they end, so its last line is taken to have ended.
+func TestAttempt(tester Tester, attempt api.Attempt) api.Verdict {
+	combined := CombinedText(attempt.Combined)
+	if exact, ok := tester.(ExactTester); ok {
+		if len(attempt.Combined) > 0 {
+			combined += "\n"
+		}
+		return exact.TestExact(attempt.RawStdout, attempt.RawStderr, combined)
+	}
+	return tester.Test(attempt.Stdout, attempt.Stderr, combined)
+}
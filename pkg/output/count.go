@@ -0,0 +1,134 @@
+package output
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+)
+
+// NewMatchCountTester returns a new Tester that tests how many times the internal pattern matches the targeted input
+func NewMatchCountTester(target api.OutputTarget, pattern *regexp.Regexp, count api.CountComparison) Tester {
+	return &matchCountTester{target: target, pattern: pattern, count: count}
+}
+
+// matchCountTester tests how many times the internal pattern matches the targeted input
+type matchCountTester struct {
+	// target is the stream that is tested
+	target api.OutputTarget
+
+	// pattern is the regular expression that is used to test input
+	pattern *regexp.Regexp
+
+	// count is the comparison the number of matches must satisfy
+	count api.CountComparison
+}
+
+// Test determines if the number of non-overlapping matches across the targeted streams satisfies the comparison
+func (t *matchCountTester) Test(stdout, stderr, combined string) api.Verdict {
+	var spans []api.Span
+	for _, stream := range targetedStreams(t.target, stdout, stderr, combined) {
+		spans = append(spans, findSpans(t.pattern, stream)...)
+	}
+	if CompareCount(len(spans), t.count) {
+		return api.Verdict{Passed: true, Reason: fmt.Sprintf("Command output%s matched %#q %s", describeTarget(t.target), t.pattern, pluralize(len(spans), "time")), Spans: spans}
+	}
+	return api.Verdict{Reason: fmt.Sprintf("Command output%s matched %#q %s, expected %s", describeTarget(t.target), t.pattern, pluralize(len(spans), "time"), DescribeCount(t.count, "time")), Spans: spans}
+}
+
+// NewLineCountTester returns a new Tester that tests how many lines the targeted input has
+func NewLineCountTester(target api.OutputTarget, count api.CountComparison) Tester {
+	return &lineCountTester{target: target, count: count}
+}
+
+// lineCountTester tests how many lines the targeted input has
+type lineCountTester struct {
+	// target is the stream that is tested
+	target api.OutputTarget
+
+	// count is the comparison the number of lines must satisfy
+	count api.CountComparison
+}
+
+var _ ExactTester = &lineCountTester{}
+
+// Test determines if the number of lines across the targeted streams satisfies the comparison, where blank lines at
+// the end of the output cannot be counted as they were trimmed with the trailing newline
+func (t *lineCountTester) Test(stdout, stderr, combined string) api.Verdict {
+	return t.test(targetedStreams(t.target, stdout, stderr, combined), true)
+}
+
+// TestExact determines if the number of lines across the targeted streams satisfies the comparison, counting blank
+// lines at the end of the output as well
+func (t *lineCountTester) TestExact(stdout, stderr, combined string) api.Verdict {
+	return t.test(targetedStreams(t.target, stdout, stderr, combined), false)
+}
+
+// test determines if the number of lines across the streams satisfies the comparison
+func (t *lineCountTester) test(streams []stream, trimmed bool) api.Verdict {
+	lines := 0
+	for _, stream := range streams {
+		lines += countLines(stream.text, trimmed)
+	}
+	if CompareCount(lines, t.count) {
+		return api.Verdict{Passed: true, Reason: fmt.Sprintf("Command output%s had %s", describeTarget(t.target), pluralize(lines, "line"))}
+	}
+	return api.Verdict{Reason: fmt.Sprintf("Command output%s had %s, expected %s", describeTarget(t.target), pluralize(lines, "line"), DescribeCount(t.count, "line"))}
+}
+
+// countLines counts the lines in captured output, each of which is ended by a newline except for a last line that
+// was not or that had its newline trimmed
+func countLines(output string, trimmed bool) int {
+	if len(output) == 0 {
+		return 0
+	}
+	if !trimmed && strings.HasSuffix(output, "\n") {
+		return strings.Count(output, "\n")
+	}
+	return strings.Count(output, "\n") + 1
+}
+
+// CompareCount determines if a count satisfies a comparison
+func CompareCount(count int, comparison api.CountComparison) bool {
+	switch comparison.Operator {
+	case api.CountOperatorAtLeast:
+		return count >= comparison.Count
+	case api.CountOperatorAtMost:
+		return count <= comparison.Count
+	default:
+		return count == comparison.Count
+	}
+}
+
+// DescribeCount describes a comparison of a count of things, like "at least 3 lines"
+func DescribeCount(comparison api.CountComparison, thing string) string {
+	switch comparison.Operator {
+	case api.CountOperatorAtLeast:
+		return fmt.Sprintf("at least %s", pluralize(comparison.Count, thing))
+	case api.CountOperatorAtMost:
+		return fmt.Sprintf("at most %s", pluralize(comparison.Count, thing))
+	default:
+		return fmt.Sprintf("exactly %s", pluralize(comparison.Count, thing))
+	}
+}
+
+// pluralize formats a count of things, like "1 line" or "2 lines"
+func pluralize(count int, thing string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, thing)
+	}
+	return fmt.Sprintf("%d %ss", count, thing)
+}
+
+// describeTarget describes the stream that is tested, or nothing if any stream is tested
+func describeTarget(target api.OutputTarget) string {
+	switch target {
+	case api.OutputTargetStdout, api.OutputTargetStderr:
+		return fmt.Sprintf(" to %s", target)
+	case api.OutputTargetCombined:
+		return " to stdout and stderr combined"
+	default:
+		return ""
+	}
+}
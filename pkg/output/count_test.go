@@ -0,0 +1,207 @@
+package output
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+)
+
+func TestMatchCountTester(t *testing.T) {
+	testCases := []struct {
+		name           string
+		target         api.OutputTarget
+		regex          *regexp.Regexp
+		count          api.CountComparison
+		stdout         string
+		stderr         string
+		expectedPassed bool
+		expectedReason string
+	}{
+		{
+			name:           "regex matches exactly as many times as expected across stdout and stderr",
+			regex:          regexp.MustCompile(`o`),
+			count:          api.CountComparison{Operator: api.CountOperatorExactly, Count: 2},
+			stdout:         "hello",
+			stderr:         "world",
+			expectedPassed: true,
+			expectedReason: "Command output matched `o` 2 times",
+		},
+		{
+			name:           "regex matches fewer times than expected",
+			target:         api.OutputTargetStdout,
+			regex:          regexp.MustCompile(`l`),
+			count:          api.CountComparison{Operator: api.CountOperatorExactly, Count: 3},
+			stdout:         "hello",
+			stderr:         "world",
+			expectedReason: "Command output to stdout matched `l` 2 times, expected exactly 3 times",
+		},
+		{
+			name:           "regex matches at least as many times as expected",
+			regex:          regexp.MustCompile(`l`),
+			count:          api.CountComparison{Operator: api.CountOperatorAtLeast, Count: 3},
+			stdout:         "hello",
+			stderr:         "world",
+			expectedPassed: true,
+			expectedReason: "Command output matched `l` 3 times",
+		},
+		{
+			name:           "regex matches more times than allowed",
+			target:         api.OutputTargetStderr,
+			regex:          regexp.MustCompile(`[a-z]`),
+			count:          api.CountComparison{Operator: api.CountOperatorAtMost, Count: 1},
+			stdout:         "hello",
+			stderr:         "world",
+			expectedReason: "Command output to stderr matched `[a-z]` 5 times, expected at most 1 time",
+		},
+		{
+			name:           "regex does not match at all",
+			regex:          regexp.MustCompile(`[0-9]`),
+			count:          api.CountComparison{Operator: api.CountOperatorAtLeast, Count: 1},
+			stdout:         "hello",
+			stderr:         "world",
+			expectedReason: "Command output matched `[0-9]` 0 times, expected at least 1 time",
+		},
+	}
+
+	for _, testCase := range testCases {
+		verdict := NewMatchCountTester(testCase.target, testCase.regex, testCase.count).Test(testCase.stdout, testCase.stderr, "")
+		if expected, actual := testCase.expectedPassed, verdict.Passed; expected != actual {
+			t.Errorf("%s: match count tester did not generate correct result: expected %v, got %v", testCase.name, expected, actual)
+		}
+		if expected, actual := testCase.expectedReason, verdict.Reason; expected != actual {
+			t.Errorf("%s: match count tester did not generate correct reason: expected %q, got %q", testCase.name, expected, actual)
+		}
+	}
+}
+
+func TestLineCountTester(t *testing.T) {
+	testCases := []struct {
+		name           string
+		target         api.OutputTarget
+		count          api.CountComparison
+		stdout         string
+		stderr         string
+		combined       string
+		expectedPassed bool
+		expectedReason string
+	}{
+		{
+			name:           "empty output has no lines",
+			target:         api.OutputTargetStderr,
+			count:          api.CountComparison{Operator: api.CountOperatorExactly, Count: 0},
+			stdout:         "hello",
+			expectedPassed: true,
+			expectedReason: "Command output to stderr had 0 lines",
+		},
+		{
+			name:           "output expected to be empty has lines",
+			target:         api.OutputTargetStderr,
+			count:          api.CountComparison{Operator: api.CountOperatorExactly, Count: 0},
+			stderr:         "oops",
+			expectedReason: "Command output to stderr had 1 line, expected exactly 0 lines",
+		},
+		{
+			name:           "lines are counted across stdout and stderr",
+			count:          api.CountComparison{Operator: api.CountOperatorExactly, Count: 3},
+			stdout:         "a\nb",
+			stderr:         "c",
+			expectedPassed: true,
+			expectedReason: "Command output had 3 lines",
+		},
+		{
+			name:           "combined output has fewer lines than expected",
+			target:         api.OutputTargetCombined,
+			count:          api.CountComparison{Operator: api.CountOperatorAtLeast, Count: 4},
+			stdout:         "a\nb",
+			stderr:         "c",
+			combined:       "a\nc\nb",
+			expectedReason: "Command output to stdout and stderr combined had 3 lines, expected at least 4 lines",
+		},
+		{
+			name:           "stdout has more lines than allowed",
+			target:         api.OutputTargetStdout,
+			count:          api.CountComparison{Operator: api.CountOperatorAtMost, Count: 1},
+			stdout:         "a\nb",
+			expectedReason: "Command output to stdout had 2 lines, expected at most 1 line",
+		},
+	}
+
+	for _, testCase := range testCases {
+		verdict := NewLineCountTester(testCase.target, testCase.count).Test(testCase.stdout, testCase.stderr, testCase.combined)
+		if expected, actual := testCase.expectedPassed, verdict.Passed; expected != actual {
+			t.Errorf("%s: line count tester did not generate correct result: expected %v, got %v", testCase.name, expected, actual)
+		}
+		if expected, actual := testCase.expectedReason, verdict.Reason; expected != actual {
+			t.Errorf("%s: line count tester did not generate correct reason: expected %q, got %q", testCase.name, expected, actual)
+		}
+	}
+}
+
+func TestLineCountTesterExact(t *testing.T) {
+	testCases := []struct {
+		name           string
+		target         api.OutputTarget
+		count          api.CountComparison
+		stdout         string
+		stderr         string
+		combined       string
+		expectedPassed bool
+		expectedReason string
+	}{
+		{
+			name:           "only the final newline does not start a line",
+			target:         api.OutputTargetStdout,
+			count:          api.CountComparison{Operator: api.CountOperatorExactly, Count: 1},
+			stdout:         "a\n",
+			expectedPassed: true,
+			expectedReason: "Command output to stdout had 1 line",
+		},
+		{
+			name:           "trailing blank lines are counted",
+			target:         api.OutputTargetStdout,
+			count:          api.CountComparison{Operator: api.CountOperatorExactly, Count: 3},
+			stdout:         "a\n\n\n",
+			expectedPassed: true,
+			expectedReason: "Command output to stdout had 3 lines",
+		},
+		{
+			name:           "output of only newlines is not empty",
+			target:         api.OutputTargetStderr,
+			count:          api.CountComparison{Operator: api.CountOperatorExactly, Count: 0},
+			stderr:         "\n\n",
+			expectedReason: "Command output to stderr had 2 lines, expected exactly 0 lines",
+		},
+		{
+			name:           "a single newline is a blank line",
+			target:         api.OutputTargetCombined,
+			count:          api.CountComparison{Operator: api.CountOperatorExactly, Count: 1},
+			combined:       "\n",
+			expectedPassed: true,
+			expectedReason: "Command output to stdout and stderr combined had 1 line",
+		},
+		{
+			name:           "output without a final newline",
+			count:          api.CountComparison{Operator: api.CountOperatorExactly, Count: 3},
+			stdout:         "a\n\nb",
+			expectedPassed: true,
+			expectedReason: "Command output had 3 lines",
+		},
+		{
+			name:           "empty output has no lines",
+			count:          api.CountComparison{Operator: api.CountOperatorExactly, Count: 0},
+			expectedPassed: true,
+			expectedReason: "Command output had 0 lines",
+		},
+	}
+
+	for _, testCase := range testCases {
+		verdict := NewLineCountTester(testCase.target, testCase.count).(ExactTester).TestExact(testCase.stdout, testCase.stderr, testCase.combined)
+		if expected, actual := testCase.expectedPassed, verdict.Passed; expected != actual {
+			t.Errorf("%s: line count tester did not generate correct result: expected %v, got %v", testCase.name, expected, actual)
+		}
+		if expected, actual := testCase.expectedReason, verdict.Reason; expected != actual {
+			t.Errorf("%s: line count tester did not generate correct reason: expected %q, got %q", testCase.name, expected, actual)
+		}
+	}
+}
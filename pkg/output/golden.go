@@ -0,0 +1,74 @@
+package output
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+	"github.com/stevekuznetsov/exec-assert/pkg/util"
+)
+
+// NewGoldenTester returns a new Tester that tests if the targeted output is exactly the content of a golden file,
+// or that rewrites the golden file with the targeted output if it is updating
+func NewGoldenTester(target api.OutputTarget, path string, update bool) Tester {
+	return &goldenTester{target: target, path: path, update: update}
+}
+
+// goldenTester tests if the targeted output is exactly the content of a golden file
+type goldenTester struct {
+	// target is the stream that is tested
+	target api.OutputTarget
+
+	// path is the path to the golden file
+	path string
+
+	// update determines if the golden file is rewritten with the output instead of being compared to it
+	update bool
+}
+
+// Test determines if the targeted stream is the content of the golden file when the stream has had its trailing
+// newlines trimmed, in which case they are not compared
+func (t *goldenTester) Test(stdout, stderr, combined string) api.Verdict {
+	return t.test(targetedStreams(t.target, stdout, stderr, combined)[0].text, true)
+}
+
+// TestExact determines if the targeted stream is exactly the content of the golden file, trailing newlines and all
+func (t *goldenTester) TestExact(stdout, stderr, combined string) api.Verdict {
+	return t.test(targetedStreams(t.target, stdout, stderr, combined)[0].text, false)
+}
+
+// test determines if the output is the content of the golden file, updating the file first if the tester is
+// updating. A unified diff from the golden file to the output is shown when they differ.
+func (t *goldenTester) test(actual string, trimmed bool) api.Verdict {
+	if t.update {
+		if err := os.MkdirAll(filepath.Dir(t.path), 0755); err != nil {
+			return api.Verdict{Reason: fmt.Sprintf("failed to create directory for golden file %s: %v", t.path, err)}
+		}
+		if trimmed {
+			// the trailing newline was trimmed from the output, so we add one back to keep the file well-formed
+			actual += "\n"
+		}
+		if err := ioutil.WriteFile(t.path, []byte(actual), 0644); err != nil {
+			return api.Verdict{Reason: fmt.Sprintf("failed to update golden file %s: %v", t.path, err)}
+		}
+		return api.Verdict{Passed: true, Reason: fmt.Sprintf("Updated golden file %s with the command output to %s", t.path, t.target)}
+	}
+
+	data, err := ioutil.ReadFile(t.path)
+	if err != nil {
+		return api.Verdict{Reason: fmt.Sprintf("failed to read golden file %s: %v", t.path, err)}
+	}
+	expected := string(data)
+	if trimmed {
+		expected = strings.TrimRight(expected, "\n")
+	}
+
+	diff := util.UnifiedDiff(t.path, string(t.target), expected, actual)
+	if len(diff) == 0 {
+		return api.Verdict{Passed: true, Reason: fmt.Sprintf("Command output to %s matched golden file %s", t.target, t.path)}
+	}
+	return api.Verdict{Reason: fmt.Sprintf("Command output to %s did not match golden file %s:\n%s", t.target, t.path, strings.TrimRight(diff, "\n"))}
+}
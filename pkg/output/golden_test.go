@@ -0,0 +1,149 @@
+package output
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+)
+
+func TestGoldenTester(t *testing.T) {
+	testCases := []struct {
+		name           string
+		target         api.OutputTarget
+		golden         string
+		stdout         string
+		stderr         string
+		expectedPassed bool
+		expectedReason string
+	}{
+		{
+			name:           "stdout matches golden file",
+			target:         api.OutputTargetStdout,
+			golden:         "hello\nworld\n",
+			stdout:         "hello\nworld",
+			stderr:         "other",
+			expectedPassed: true,
+			expectedReason: "Command output to stdout matched golden file GOLDEN",
+		},
+		{
+			name:           "stderr differs from golden file",
+			target:         api.OutputTargetStderr,
+			golden:         "hello\nworld\n",
+			stdout:         "hello\nworld",
+			stderr:         "hello\nthere",
+			expectedPassed: false,
+			expectedReason: `Command output to stderr did not match golden file GOLDEN:
+--- GOLDEN
++++ stderr
+@@ -1,2 +1,2 @@
+ hello
+-world
++there`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		dir, err := ioutil.TempDir("", "golden")
+		if err != nil {
+			t.Fatalf("%s: failed to create temporary directory: %v", testCase.name, err)
+		}
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "golden")
+		if err := ioutil.WriteFile(path, []byte(testCase.golden), 0644); err != nil {
+			t.Fatalf("%s: failed to write golden file: %v", testCase.name, err)
+		}
+
+		verdict := NewGoldenTester(testCase.target, path, false).Test(testCase.stdout, testCase.stderr, "")
+		if expected, actual := testCase.expectedPassed, verdict.Passed; expected != actual {
+			t.Errorf("%s: golden tester did not generate correct result: expected %v, got %v", testCase.name, expected, actual)
+		}
+
+		if expected, actual := strings.Replace(testCase.expectedReason, "GOLDEN", path, -1), verdict.Reason; expected != actual {
+			t.Errorf("%s: golden tester did not generate correct reason:\nexpected:\n%s\ngot:\n%s", testCase.name, expected, actual)
+		}
+	}
+}
+
+func TestGoldenTesterUpdate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "golden")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "nested", "golden")
+	if !NewGoldenTester(api.OutputTargetStdout, path, true).Test("hello\nworld", "", "").Passed {
+		t.Errorf("updating golden tester did not succeed")
+	}
+
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read updated golden file: %v", err)
+	}
+	if expected, actual := "hello\nworld\n", string(contents); expected != actual {
+		t.Errorf("updating golden tester did not write correct golden file: expected %q, got %q", expected, actual)
+	}
+
+	if !NewGoldenTester(api.OutputTargetStdout, path, false).Test("hello\nworld", "", "").Passed {
+		t.Errorf("golden tester did not succeed against updated golden file")
+	}
+}
+
+func TestGoldenTesterExact(t *testing.T) {
+	dir, err := ioutil.TempDir("", "golden")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "golden")
+	if err := ioutil.WriteFile(path, []byte("hi\n"), 0644); err != nil {
+		t.Fatalf("failed to write golden file: %v", err)
+	}
+
+	testCases := []struct {
+		name           string
+		stdout         string
+		expectedPassed bool
+	}{
+		{
+			name:           "output matches golden file exactly",
+			stdout:         "hi\n",
+			expectedPassed: true,
+		},
+		{
+			name:           "output has more trailing newlines than golden file",
+			stdout:         "hi\n\n\n",
+			expectedPassed: false,
+		},
+		{
+			name:           "output has no trailing newline",
+			stdout:         "hi",
+			expectedPassed: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		attempt := api.Attempt{Stdout: strings.TrimRight(testCase.stdout, "\n"), RawStdout: testCase.stdout}
+		if expected, actual := testCase.expectedPassed, TestAttempt(NewGoldenTester(api.OutputTargetStdout, path, false), attempt).Passed; expected != actual {
+			t.Errorf("%s: golden tester did not generate correct result: expected %v, got %v", testCase.name, expected, actual)
+		}
+	}
+
+	updated := filepath.Join(dir, "updated")
+	if !TestAttempt(NewGoldenTester(api.OutputTargetStdout, updated, true), api.Attempt{Stdout: "hi", RawStdout: "hi\n\n"}).Passed {
+		t.Errorf("updating golden tester did not succeed")
+	}
+	contents, err := ioutil.ReadFile(updated)
+	if err != nil {
+		t.Fatalf("failed to read updated golden file: %v", err)
+	}
+	if expected, actual := "hi\n\n", string(contents); expected != actual {
+		t.Errorf("updating golden tester did not write correct golden file: expected %q, got %q", expected, actual)
+	}
+}
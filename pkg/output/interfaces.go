@@ -1,7 +1,21 @@
 package output
 
+import "github.com/stevekuznetsov/exec-assert/pkg/api"
+
 // Tester knows how to test stdout and stderr for a condition
 type Tester interface {
-	// Test tests stdout and stderr for a condition
-	Test(stdout, stderr string) (success bool)
+	// Test tests stdout, stderr and the output to both streams combined in the order
+	// it was written for a condition, giving a verdict that explains why the condition
+	// was or was not met and where in the output it matched
+	Test(stdout, stderr, combined string) (verdict api.Verdict)
+}
+
+// ExactTester knows how to test stdout and stderr exactly as they were written, where a
+// Tester is given them with their trailing newlines trimmed
+type ExactTester interface {
+	Tester
+
+	// TestExact tests the output for a condition like Test does, but is given every trailing
+	// newline that was written as well
+	TestExact(stdout, stderr, combined string) (verdict api.Verdict)
 }
@@ -0,0 +1,311 @@
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+)
+
+// JSONQuery is a query on a JSON document, like `.items[0].status.phase == "Running"` or `.items | length >= 3`.
+// A query is a pipeline of stages, each of which is either a path into the document or `length`, optionally
+// followed by a comparison of the result with a JSON literal. A query without a comparison holds if the result
+// is neither null nor false.
+type JSONQuery struct {
+	// expression is the query as it was written
+	expression string
+
+	// stages are the stages of the pipeline
+	stages []jsonStage
+
+	// operator is the comparison operator, if the query makes a comparison
+	operator string
+
+	// operand is the JSON literal the result is compared with, if the query makes a comparison
+	operand interface{}
+}
+
+// jsonStage is one stage in a query pipeline
+type jsonStage struct {
+	// length determines if the stage takes the length of its input instead of following a path
+	length bool
+
+	// path is the sequence of object keys and array indices to follow; keys are strings and indices are ints
+	path []interface{}
+}
+
+// jsonOperators are the comparison operators, longest first so that `<=` is not mistaken for `<`
+var jsonOperators = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+// ParseJSONQuery parses a query on a JSON document
+func ParseJSONQuery(expression string) (*JSONQuery, error) {
+	query := &JSONQuery{expression: expression}
+
+	pipeline := expression
+	if index, operator := findJSONOperator(expression); index >= 0 {
+		pipeline = expression[:index]
+		query.operator = operator
+		if err := json.Unmarshal([]byte(strings.TrimSpace(expression[index+len(operator):])), &query.operand); err != nil {
+			return nil, fmt.Errorf("failed to parse the right-hand side of %q as JSON: %v", expression, err)
+		}
+	}
+
+	for _, stage := range splitUnquoted(pipeline, '|') {
+		parsedStage, err := parseJSONStage(strings.TrimSpace(stage))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %q: %v", expression, err)
+		}
+		query.stages = append(query.stages, parsedStage)
+	}
+
+	return query, nil
+}
+
+// findJSONOperator finds the first comparison operator outside of a quoted string
+func findJSONOperator(expression string) (int, string) {
+	quoted := false
+	for i := 0; i < len(expression); i++ {
+		switch {
+		case expression[i] == '\\' && quoted:
+			i++
+		case expression[i] == '"':
+			quoted = !quoted
+		case !quoted:
+			for _, operator := range jsonOperators {
+				if strings.HasPrefix(expression[i:], operator) {
+					return i, operator
+				}
+			}
+		}
+	}
+	return -1, ""
+}
+
+// splitUnquoted splits text on a separator that is outside of a quoted string
+func splitUnquoted(text string, separator byte) []string {
+	var parts []string
+	quoted, start := false, 0
+	for i := 0; i < len(text); i++ {
+		switch {
+		case text[i] == '\\' && quoted:
+			i++
+		case text[i] == '"':
+			quoted = !quoted
+		case text[i] == separator && !quoted:
+			parts = append(parts, text[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, text[start:])
+}
+
+// parseJSONStage parses one stage of a query pipeline: `length`, `.`, or a path like `.items[0]["key"].name`
+func parseJSONStage(stage string) (jsonStage, error) {
+	if stage == "length" {
+		return jsonStage{length: true}, nil
+	}
+
+	if !strings.HasPrefix(stage, ".") {
+		return jsonStage{}, fmt.Errorf("expected a path starting with `.` or `length`, got %q", stage)
+	}
+
+	var path []interface{}
+	for rest := stage; len(rest) > 0; {
+		switch {
+		case rest == ".":
+			rest = ""
+		case strings.HasPrefix(rest, ".["):
+			rest = rest[1:]
+		case strings.HasPrefix(rest, "."):
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			if end == 0 {
+				return jsonStage{}, fmt.Errorf("expected a key after `.` in %q", stage)
+			}
+			path = append(path, rest[1:end+1])
+			rest = rest[end+1:]
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return jsonStage{}, fmt.Errorf("expected `]` in %q", stage)
+			}
+			subscript := rest[1:end]
+			if strings.HasPrefix(subscript, `"`) {
+				var key string
+				if err := json.Unmarshal([]byte(subscript), &key); err != nil {
+					return jsonStage{}, fmt.Errorf("failed to parse key %s: %v", subscript, err)
+				}
+				path = append(path, key)
+			} else {
+				index, err := strconv.Atoi(subscript)
+				if err != nil {
+					return jsonStage{}, fmt.Errorf("expected an array index or quoted key, got %q", subscript)
+				}
+				path = append(path, index)
+			}
+			rest = rest[end+1:]
+		default:
+			return jsonStage{}, fmt.Errorf("unexpected %q in %q", rest, stage)
+		}
+	}
+
+	return jsonStage{path: path}, nil
+}
+
+// String returns the query as it was written
+func (q *JSONQuery) String() string {
+	return q.expression
+}
+
+// Evaluate evaluates the query on a document, returning whether the query holds and the value that the
+// pipeline resulted in, or an error if the pipeline could not be followed
+func (q *JSONQuery) Evaluate(document interface{}) (bool, interface{}, error) {
+	value := document
+	for _, stage := range q.stages {
+		var err error
+		if value, err = stage.evaluate(value); err != nil {
+			return false, nil, err
+		}
+	}
+
+	if len(q.operator) == 0 {
+		return value != nil && value != false, value, nil
+	}
+
+	holds, err := compareJSON(value, q.operator, q.operand)
+	return holds, value, err
+}
+
+// evaluate evaluates one stage of a query pipeline
+func (s jsonStage) evaluate(value interface{}) (interface{}, error) {
+	if s.length {
+		switch typed := value.(type) {
+		case []interface{}:
+			return float64(len(typed)), nil
+		case map[string]interface{}:
+			return float64(len(typed)), nil
+		case string:
+			return float64(len(typed)), nil
+		case nil:
+			return float64(0), nil
+		default:
+			return nil, fmt.Errorf("cannot take the length of %s", formatJSON(value))
+		}
+	}
+
+	for _, step := range s.path {
+		switch typedStep := step.(type) {
+		case string:
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("cannot look up key %q in %s", typedStep, formatJSON(value))
+			}
+			if value, ok = object[typedStep]; !ok {
+				return nil, fmt.Errorf("no key %q in object", typedStep)
+			}
+		case int:
+			array, ok := value.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("cannot index %s", formatJSON(value))
+			}
+			index := typedStep
+			if index < 0 {
+				// negative indices count back from the end of the array
+				index += len(array)
+			}
+			if index < 0 || index >= len(array) {
+				return nil, fmt.Errorf("index %d out of range for array of length %d", typedStep, len(array))
+			}
+			value = array[index]
+		}
+	}
+	return value, nil
+}
+
+// compareJSON compares two JSON values; equality applies to any values, but ordering only applies to numbers or strings
+func compareJSON(left interface{}, operator string, right interface{}) (bool, error) {
+	switch operator {
+	case "==":
+		return reflect.DeepEqual(left, right), nil
+	case "!=":
+		return !reflect.DeepEqual(left, right), nil
+	}
+
+	var comparison int
+	switch typedLeft := left.(type) {
+	case float64:
+		typedRight, ok := right.(float64)
+		if !ok {
+			return false, fmt.Errorf("cannot compare %s with %s", formatJSON(left), formatJSON(right))
+		}
+		switch {
+		case typedLeft < typedRight:
+			comparison = -1
+		case typedLeft > typedRight:
+			comparison = 1
+		}
+	case string:
+		typedRight, ok := right.(string)
+		if !ok {
+			return false, fmt.Errorf("cannot compare %s with %s", formatJSON(left), formatJSON(right))
+		}
+		comparison = strings.Compare(typedLeft, typedRight)
+	default:
+		return false, errors.New("only numbers and strings can be ordered")
+	}
+
+	switch operator {
+	case "<":
+		return comparison < 0, nil
+	case "<=":
+		return comparison <= 0, nil
+	case ">":
+		return comparison > 0, nil
+	default:
+		return comparison >= 0, nil
+	}
+}
+
+// formatJSON formats a JSON value for display
+func formatJSON(value interface{}) string {
+	formatted, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(formatted)
+}
+
+// NewJSONTester returns a new Tester that tests if stdout is a JSON document on which the query holds
+func NewJSONTester(query *JSONQuery) Tester {
+	return &jsonTester{query: query}
+}
+
+// jsonTester tests if stdout is a JSON document on which the query holds
+type jsonTester struct {
+	// query is the query that must hold on the document
+	query *JSONQuery
+}
+
+// Test determines if stdout is a JSON document on which the query holds, explaining why stdout is not JSON or
+// why the query does not hold on it and showing the value that was found
+func (t *jsonTester) Test(stdout, stderr, combined string) api.Verdict {
+	var document interface{}
+	if err := json.Unmarshal([]byte(stdout), &document); err != nil {
+		return api.Verdict{Reason: fmt.Sprintf("Command output to stdout is not JSON, so %#q could not hold: %v", t.query, err)}
+	}
+
+	holds, value, err := t.query.Evaluate(document)
+	if err != nil {
+		return api.Verdict{Reason: fmt.Sprintf("Command output to stdout did not hold for %#q: %v", t.query, err)}
+	}
+	if !holds {
+		return api.Verdict{Reason: fmt.Sprintf("Command output to stdout did not hold for %#q: found %s", t.query, formatJSON(value))}
+	}
+	return api.Verdict{Passed: true, Reason: fmt.Sprintf("Command output to stdout held for %#q: found %s", t.query, formatJSON(value))}
+}
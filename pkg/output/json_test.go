@@ -0,0 +1,118 @@
+package output
+
+import "testing"
+
+func TestJSONTester(t *testing.T) {
+	document := `{"items": [{"name": "first", "status": {"phase": "Running"}}, {"name": "second", "status": {"phase": "Pending"}}], "a.b": true}`
+
+	testCases := []struct {
+		name           string
+		query          string
+		stdout         string
+		expectedPassed bool
+		expectedReason string
+	}{
+		{
+			name:           "nested value equals string",
+			query:          `.items[0].status.phase == "Running"`,
+			stdout:         document,
+			expectedPassed: true,
+			expectedReason: "Command output to stdout held for `.items[0].status.phase == \"Running\"`: found \"Running\"",
+		},
+		{
+			name:           "nested value does not equal string",
+			query:          `.items[1].status.phase == "Running"`,
+			stdout:         document,
+			expectedPassed: false,
+			expectedReason: "Command output to stdout did not hold for `.items[1].status.phase == \"Running\"`: found \"Pending\"",
+		},
+		{
+			name:           "negative index",
+			query:          `.items[-1].name != "first"`,
+			stdout:         document,
+			expectedPassed: true,
+			expectedReason: "Command output to stdout held for `.items[-1].name != \"first\"`: found \"second\"",
+		},
+		{
+			name:           "length compared with number",
+			query:          `.items | length >= 2`,
+			stdout:         document,
+			expectedPassed: true,
+			expectedReason: "Command output to stdout held for `.items | length >= 2`: found 2",
+		},
+		{
+			name:           "length fails comparison with number",
+			query:          `.items | length > 2`,
+			stdout:         document,
+			expectedPassed: false,
+			expectedReason: "Command output to stdout did not hold for `.items | length > 2`: found 2",
+		},
+		{
+			name:           "quoted key",
+			query:          `.["a.b"]`,
+			stdout:         document,
+			expectedPassed: true,
+			expectedReason: "Command output to stdout held for `.[\"a.b\"]`: found true",
+		},
+		{
+			name:           "object equals literal",
+			query:          `.items[0].status == {"phase": "Running"}`,
+			stdout:         document,
+			expectedPassed: true,
+			expectedReason: "Command output to stdout held for `.items[0].status == {\"phase\": \"Running\"}`: found {\"phase\":\"Running\"}",
+		},
+		{
+			name:           "missing key",
+			query:          `.items[0].missing == 1`,
+			stdout:         document,
+			expectedPassed: false,
+			expectedReason: "Command output to stdout did not hold for `.items[0].missing == 1`: no key \"missing\" in object",
+		},
+		{
+			name:           "index out of range",
+			query:          `.items[2]`,
+			stdout:         document,
+			expectedPassed: false,
+			expectedReason: "Command output to stdout did not hold for `.items[2]`: index 2 out of range for array of length 2",
+		},
+		{
+			name:           "null value is not truthy",
+			query:          `.value`,
+			stdout:         `{"value": null}`,
+			expectedPassed: false,
+			expectedReason: "Command output to stdout did not hold for `.value`: found null",
+		},
+		{
+			name:           "stdout is not JSON",
+			query:          `.`,
+			stdout:         `not json`,
+			expectedPassed: false,
+			expectedReason: "Command output to stdout is not JSON, so `.` could not hold: invalid character 'o' in literal null (expecting 'u')",
+		},
+	}
+
+	for _, testCase := range testCases {
+		query, err := ParseJSONQuery(testCase.query)
+		if err != nil {
+			t.Errorf("%s: failed to parse query: %v", testCase.name, err)
+			continue
+		}
+
+		verdict := NewJSONTester(query).Test(testCase.stdout, "", "")
+		if expected, actual := testCase.expectedPassed, verdict.Passed; expected != actual {
+			t.Errorf("%s: JSON tester did not generate correct result: expected %v, got %v", testCase.name, expected, actual)
+		}
+
+		if expected, actual := testCase.expectedReason, verdict.Reason; expected != actual {
+			t.Errorf("%s: JSON tester did not generate correct reason: expected %q, got %q", testCase.name, expected, actual)
+		}
+	}
+}
+
+func TestParseJSONQueryErrors(t *testing.T) {
+	for _, query := range []string{`items`, `.items[x]`, `.items[0`, `.a == nope`, `.a | count`} {
+		if _, err := ParseJSONQuery(query); err == nil {
+			t.Errorf("expected an error parsing query %q, got none", query)
+		}
+	}
+}
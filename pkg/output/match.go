@@ -0,0 +1,67 @@
+package output
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+)
+
+// CompileTest compiles an output test to the regular expression that testers match output with, interpreting
+// the test as literal text, a glob that must match a whole line, or a regular expression
+func CompileTest(test string, mode api.MatchMode) (*regexp.Regexp, error) {
+	switch mode {
+	case api.MatchModeLiteral:
+		return regexp.MustCompile(regexp.QuoteMeta(test)), nil
+	case api.MatchModeGlob:
+		compiled, err := regexp.Compile(translateGlob(test))
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile output test %q as a glob: %v", test, err)
+		}
+		return compiled, nil
+	default:
+		compiled, err := regexp.Compile(test)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile output test %q to regular expression: %v", test, err)
+		}
+		return compiled, nil
+	}
+}
+
+// translateGlob translates a glob to a regular expression that matches whole lines: `*` matches any run of
+// characters on a line, `?` matches any one character on a line, `[...]` and `[!...]` match any character in
+// or out of a class, and `\` matches the character after it literally
+func translateGlob(glob string) string {
+	var expression strings.Builder
+	expression.WriteString(`(?m)^`)
+	for i := 0; i < len(glob); i++ {
+		switch glob[i] {
+		case '*':
+			expression.WriteString(`[^\n]*`)
+		case '?':
+			expression.WriteString(`[^\n]`)
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+			}
+			expression.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		case '[':
+			end := strings.Index(glob[i+1:], "]")
+			if end < 0 {
+				expression.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + strings.TrimPrefix(class, "!")
+			}
+			expression.WriteString("[" + strings.Replace(class, `\`, `\\`, -1) + "]")
+			i += end + 1
+		default:
+			expression.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	expression.WriteString(`$`)
+	return expression.String()
+}
@@ -0,0 +1,95 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+)
+
+func TestCompileTest(t *testing.T) {
+	testCases := []struct {
+		name           string
+		test           string
+		mode           api.MatchMode
+		output         string
+		expectedResult bool
+		expectedError  bool
+	}{
+		{
+			name:           "literal text with regular expression characters matches",
+			test:           "[ERROR] (x.go:12)",
+			mode:           api.MatchModeLiteral,
+			output:         "before\n[ERROR] (x.go:12) something broke",
+			expectedResult: true,
+		},
+		{
+			name:           "literal text is not a regular expression",
+			test:           "a+",
+			mode:           api.MatchModeLiteral,
+			output:         "aaa",
+			expectedResult: false,
+		},
+		{
+			name:           "glob matches a whole line",
+			test:           "Listening on *:80?0",
+			mode:           api.MatchModeGlob,
+			output:         "starting\nListening on 0.0.0.0:8080\nready",
+			expectedResult: true,
+		},
+		{
+			name:           "glob does not match part of a line",
+			test:           "Listening*",
+			mode:           api.MatchModeGlob,
+			output:         "now Listening on 0.0.0.0:8080",
+			expectedResult: false,
+		},
+		{
+			name:           "glob star does not match across lines",
+			test:           "a*c",
+			mode:           api.MatchModeGlob,
+			output:         "ab\nbc",
+			expectedResult: false,
+		},
+		{
+			name:           "glob character classes match",
+			test:           "[!0-9][0-9]",
+			mode:           api.MatchModeGlob,
+			output:         "x1",
+			expectedResult: true,
+		},
+		{
+			name:           "escaped glob characters match literally",
+			test:           `\*\[ERROR]*`,
+			mode:           api.MatchModeGlob,
+			output:         "*[ERROR] oops",
+			expectedResult: true,
+		},
+		{
+			name:           "regular expression matches",
+			test:           "a+",
+			mode:           api.MatchModeRegex,
+			output:         "aaa",
+			expectedResult: true,
+		},
+		{
+			name:          "invalid regular expression",
+			test:          "a(",
+			mode:          api.MatchModeRegex,
+			expectedError: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		compiled, err := CompileTest(testCase.test, testCase.mode)
+		if expected, actual := testCase.expectedError, err != nil; expected != actual {
+			t.Errorf("%s: expected error %v, got %v", testCase.name, expected, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if expected, actual := testCase.expectedResult, compiled.MatchString(testCase.output); expected != actual {
+			t.Errorf("%s: compiled test %q did not generate correct result: expected %v, got %v", testCase.name, compiled, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package output
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+)
+
+// NewInOrderTester returns a new Tester that tests if the internal patterns match the targeted input in order
+func NewInOrderTester(target api.OutputTarget, patterns []*regexp.Regexp) Tester {
+	return &inOrderTester{target: target, patterns: patterns}
+}
+
+// inOrderTester tests if the internal patterns match the targeted input in order
+type inOrderTester struct {
+	// target is the stream that is tested; as order across streams is only known for the combined output,
+	// that is tested unless one stream is targeted
+	target api.OutputTarget
+
+	// patterns are the regular expressions that must match in order
+	patterns []*regexp.Regexp
+}
+
+// Test determines if each pattern matches the targeted input after the match of the pattern before it, explaining
+// which pattern was not found after the match of the pattern before it if one was not
+func (t *inOrderTester) Test(stdout, stderr, combined string) api.Verdict {
+	target := t.target
+	if target != api.OutputTargetStdout && target != api.OutputTargetStderr {
+		target = api.OutputTargetCombined
+	}
+	stream := targetedStreams(target, stdout, stderr, combined)[0]
+
+	var spans []api.Span
+	offset := 0
+	for i, pattern := range t.patterns {
+		match := pattern.FindStringIndex(stream.text[offset:])
+		if match == nil {
+			if i == 0 {
+				return api.Verdict{Reason: fmt.Sprintf("Command output%s did not contain %#q", describeTarget(target), pattern)}
+			}
+			return api.Verdict{Reason: fmt.Sprintf("Command output%s did not contain %#q after %#q matched on line %d", describeTarget(target), pattern, t.patterns[i-1], spans[i-1].Line), Spans: spans}
+		}
+		spans = append(spans, newSpan(stream, offset+match[0], offset+match[1]))
+		// the next pattern must match after the end of this match, so that matches do not overlap
+		offset += match[1]
+	}
+
+	lines := make([]string, len(spans))
+	for i, span := range spans {
+		lines[i] = strconv.Itoa(span.Line)
+	}
+	return api.Verdict{Passed: true, Reason: fmt.Sprintf("Command output%s contained every pattern in order, on lines %s", describeTarget(target), strings.Join(lines, ", ")), Spans: spans}
+}
@@ -0,0 +1,69 @@
+package output
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+)
+
+func TestInOrderTester(t *testing.T) {
+	testCases := []struct {
+		name           string
+		target         api.OutputTarget
+		patterns       []*regexp.Regexp
+		stdout         string
+		stderr         string
+		combined       string
+		expectedPassed bool
+		expectedReason string
+	}{
+		{
+			name:           "patterns match combined output in order",
+			patterns:       []*regexp.Regexp{regexp.MustCompile(`starting`), regexp.MustCompile(`migrating`), regexp.MustCompile(`ready`)},
+			stdout:         "starting\nready",
+			stderr:         "migrating",
+			combined:       "starting\nmigrating\nready",
+			expectedPassed: true,
+			expectedReason: "Command output to stdout and stderr combined contained every pattern in order, on lines 1, 2, 3",
+		},
+		{
+			name:           "patterns match combined output out of order",
+			patterns:       []*regexp.Regexp{regexp.MustCompile(`starting`), regexp.MustCompile(`migrating`), regexp.MustCompile(`ready`)},
+			combined:       "starting\nready\nmigrating",
+			expectedReason: "Command output to stdout and stderr combined did not contain `ready` after `migrating` matched on line 3",
+		},
+		{
+			name:           "first pattern does not match",
+			target:         api.OutputTargetStdout,
+			patterns:       []*regexp.Regexp{regexp.MustCompile(`starting`), regexp.MustCompile(`ready`)},
+			stdout:         "ready",
+			expectedReason: "Command output to stdout did not contain `starting`",
+		},
+		{
+			name:           "patterns match successive positions on one line",
+			target:         api.OutputTargetStdout,
+			patterns:       []*regexp.Regexp{regexp.MustCompile(`a`), regexp.MustCompile(`b`), regexp.MustCompile(`a`)},
+			stdout:         "abca",
+			expectedPassed: true,
+			expectedReason: "Command output to stdout contained every pattern in order, on lines 1, 1, 1",
+		},
+		{
+			name:           "matches do not overlap",
+			target:         api.OutputTargetStderr,
+			patterns:       []*regexp.Regexp{regexp.MustCompile(`ab`), regexp.MustCompile(`bc`)},
+			stderr:         "x\nabc",
+			expectedReason: "Command output to stderr did not contain `bc` after `ab` matched on line 2",
+		},
+	}
+
+	for _, testCase := range testCases {
+		verdict := NewInOrderTester(testCase.target, testCase.patterns).Test(testCase.stdout, testCase.stderr, testCase.combined)
+		if expected, actual := testCase.expectedPassed, verdict.Passed; expected != actual {
+			t.Errorf("%s: in-order tester did not generate correct result: expected %v, got %v", testCase.name, expected, actual)
+		}
+		if expected, actual := testCase.expectedReason, verdict.Reason; expected != actual {
+			t.Errorf("%s: in-order tester did not generate correct reason: expected %q, got %q", testCase.name, expected, actual)
+		}
+	}
+}
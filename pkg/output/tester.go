@@ -1,37 +1,109 @@
 package output
 
-import "regexp"
+import (
+	"fmt"
+	"regexp"
+	"strings"
 
-// NewContainsTester returns a new Tester that tests if input matches the internal pattern
-func NewContainsTester(pattern *regexp.Regexp) Tester {
-	return &containsTester{pattern: pattern}
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+)
+
+// NewContainsTester returns a new Tester that tests if the targeted input matches the internal pattern
+func NewContainsTester(target api.OutputTarget, pattern *regexp.Regexp) Tester {
+	return &containsTester{target: target, pattern: pattern}
 }
 
-// containsTester tests if input matches the internal pattern
+// containsTester tests if the targeted input matches the internal pattern
 type containsTester struct {
+	// target is the stream that is tested
+	target api.OutputTarget
+
 	// pattern is the regular expression that is used to test input
 	pattern *regexp.Regexp
 }
 
-// Test determines if stdout or stderr match the regex pattern
-func (t *containsTester) Test(stdout, stderr string) bool {
-	return t.pattern.MatchString(stdout) || t.pattern.MatchString(stderr)
+// Test determines if any of the targeted streams match the regex pattern
+func (t *containsTester) Test(stdout, stderr, combined string) api.Verdict {
+	var spans []api.Span
+	for _, stream := range targetedStreams(t.target, stdout, stderr, combined) {
+		spans = append(spans, findSpans(t.pattern, stream)...)
+	}
+	if len(spans) == 0 {
+		return api.Verdict{Reason: fmt.Sprintf("Command output%s did not contain %#q", describeTarget(t.target), t.pattern)}
+	}
+	return api.Verdict{Passed: true, Reason: fmt.Sprintf("Command output%s contained %#q %s", describeTarget(spans[0].Target), t.pattern, describeSpans(spans)), Spans: spans}
 }
 
-// NewExcludesTester returns a new Tester that tests if input doesn't match the internal pattern
-func NewExcludesTester(pattern *regexp.Regexp) Tester {
-	return &excludesTester{pattern: pattern}
+// NewExcludesTester returns a new Tester that tests if the targeted input doesn't match the internal pattern
+func NewExcludesTester(target api.OutputTarget, pattern *regexp.Regexp) Tester {
+	return &excludesTester{target: target, pattern: pattern}
 }
 
-// excludesTester tests if input doesn't match the internal pattern
+// excludesTester tests if the targeted input doesn't match the internal pattern
 type excludesTester struct {
+	// target is the stream that is tested
+	target api.OutputTarget
+
 	// pattern is the regular expression that is used to test input
 	pattern *regexp.Regexp
 }
 
-// Test determines if stdout and stderr don't match the regex pattern
-func (t *excludesTester) Test(stdout, stderr string) bool {
-	return !t.pattern.MatchString(stdout) && !t.pattern.MatchString(stderr)
+// Test determines if none of the targeted streams match the regex pattern
+func (t *excludesTester) Test(stdout, stderr, combined string) api.Verdict {
+	var spans []api.Span
+	for _, stream := range targetedStreams(t.target, stdout, stderr, combined) {
+		spans = append(spans, findSpans(t.pattern, stream)...)
+	}
+	if len(spans) == 0 {
+		return api.Verdict{Passed: true, Reason: fmt.Sprintf("Command output%s did not contain %#q", describeTarget(t.target), t.pattern)}
+	}
+	return api.Verdict{Reason: fmt.Sprintf("Command output%s contained %#q %s", describeTarget(spans[0].Target), t.pattern, describeSpans(spans)), Spans: spans}
+}
+
+// stream is the output to one stream, or to both combined, along with the target that names it
+type stream struct {
+	// target names the stream
+	target api.OutputTarget
+
+	// text is the output to the stream
+	text string
+}
+
+// targetedStreams selects the streams that a target refers to; targeting any stream selects stdout and stderr
+func targetedStreams(target api.OutputTarget, stdout, stderr, combined string) []stream {
+	switch target {
+	case api.OutputTargetStdout:
+		return []stream{{target: api.OutputTargetStdout, text: stdout}}
+	case api.OutputTargetStderr:
+		return []stream{{target: api.OutputTargetStderr, text: stderr}}
+	case api.OutputTargetCombined:
+		return []stream{{target: api.OutputTargetCombined, text: combined}}
+	default:
+		return []stream{{target: api.OutputTargetStdout, text: stdout}, {target: api.OutputTargetStderr, text: stderr}}
+	}
+}
+
+// findSpans finds every non-overlapping match of the pattern in the stream
+func findSpans(pattern *regexp.Regexp, stream stream) []api.Span {
+	var spans []api.Span
+	for _, match := range pattern.FindAllStringIndex(stream.text, -1) {
+		spans = append(spans, newSpan(stream, match[0], match[1]))
+	}
+	return spans
+}
+
+// newSpan records a match between the offsets in the stream
+func newSpan(stream stream, start, end int) api.Span {
+	return api.Span{Target: stream.target, Start: start, End: end, Line: strings.Count(stream.text[:start], "\n") + 1, Text: stream.text[start:end]}
+}
+
+// describeSpans describes where the first of the matches is, and how many others there are
+func describeSpans(spans []api.Span) string {
+	description := fmt.Sprintf("on line %d", spans[0].Line)
+	if len(spans) > 1 {
+		description = fmt.Sprintf("%s and %s", description, pluralize(len(spans)-1, "other place"))
+	}
+	return description
 }
 
 // NewAmbivalentTester returns a new Tester that never fails and doesn't test the output
@@ -43,6 +115,6 @@ func NewAmbivalentTester() Tester {
 type ambivalentTester struct{}
 
 // Test never fails and doesn't test the output
-func (t *ambivalentTester) Test(stdout, stderr string) bool {
-	return true
+func (t *ambivalentTester) Test(stdout, stderr, combined string) api.Verdict {
+	return api.Verdict{Passed: true}
 }
@@ -2,16 +2,21 @@ package output
 
 import (
 	"math/rand"
+	"reflect"
 	"regexp"
 	"testing"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
 )
 
 func TestContainsTester(t *testing.T) {
 	testCases := []struct {
 		name           string
+		target         api.OutputTarget
 		regex          *regexp.Regexp
 		stdout         string
 		stderr         string
+		combined       string
 		expectedResult bool
 	}{
 		{
@@ -42,10 +47,43 @@ func TestContainsTester(t *testing.T) {
 			stderr:         "world",
 			expectedResult: true,
 		},
+		{
+			name:           "regex matches targeted stdout",
+			target:         api.OutputTargetStdout,
+			regex:          regexp.MustCompile(`[Hh]`),
+			stdout:         "hello",
+			stderr:         "world",
+			expectedResult: true,
+		},
+		{
+			name:           "regex matches stderr but not targeted stdout",
+			target:         api.OutputTargetStdout,
+			regex:          regexp.MustCompile(`[Dd]`),
+			stdout:         "hello",
+			stderr:         "world",
+			expectedResult: false,
+		},
+		{
+			name:           "regex matches stdout but not targeted stderr",
+			target:         api.OutputTargetStderr,
+			regex:          regexp.MustCompile(`[Hh]`),
+			stdout:         "hello",
+			stderr:         "world",
+			expectedResult: false,
+		},
+		{
+			name:           "regex spanning streams matches targeted combined output",
+			target:         api.OutputTargetCombined,
+			regex:          regexp.MustCompile(`hello\nworld`),
+			stdout:         "hello",
+			stderr:         "world",
+			combined:       "hello\nworld",
+			expectedResult: true,
+		},
 	}
 
 	for _, testCase := range testCases {
-		if expected, actual := testCase.expectedResult, NewContainsTester(testCase.regex).Test(testCase.stdout, testCase.stderr); expected != actual {
+		if expected, actual := testCase.expectedResult, NewContainsTester(testCase.target, testCase.regex).Test(testCase.stdout, testCase.stderr, testCase.combined).Passed; expected != actual {
 			t.Errorf("%s: contains tester did not generate correct result: expected %v, got %v", testCase.name, expected, actual)
 		}
 	}
@@ -54,9 +92,11 @@ func TestContainsTester(t *testing.T) {
 func TestExcludesTester(t *testing.T) {
 	testCases := []struct {
 		name           string
+		target         api.OutputTarget
 		regex          *regexp.Regexp
 		stdout         string
 		stderr         string
+		combined       string
 		expectedResult bool
 	}{
 		{
@@ -87,15 +127,102 @@ func TestExcludesTester(t *testing.T) {
 			stderr:         "world",
 			expectedResult: false,
 		},
+		{
+			name:           "regex matches stdout but not targeted stderr",
+			target:         api.OutputTargetStderr,
+			regex:          regexp.MustCompile(`[Hh]`),
+			stdout:         "hello",
+			stderr:         "world",
+			expectedResult: true,
+		},
+		{
+			name:           "regex matches targeted stderr",
+			target:         api.OutputTargetStderr,
+			regex:          regexp.MustCompile(`[Dd]`),
+			stdout:         "hello",
+			stderr:         "world",
+			expectedResult: false,
+		},
+		{
+			name:           "regex matches targeted combined output",
+			target:         api.OutputTargetCombined,
+			regex:          regexp.MustCompile(`[Dd]`),
+			stdout:         "hello",
+			stderr:         "world",
+			combined:       "hello\nworld",
+			expectedResult: false,
+		},
 	}
 
 	for _, testCase := range testCases {
-		if expected, actual := testCase.expectedResult, NewExcludesTester(testCase.regex).Test(testCase.stdout, testCase.stderr); expected != actual {
+		if expected, actual := testCase.expectedResult, NewExcludesTester(testCase.target, testCase.regex).Test(testCase.stdout, testCase.stderr, testCase.combined).Passed; expected != actual {
 			t.Errorf("%s: exclude tester did not generate correct result: expected %v, got %v", testCase.name, expected, actual)
 		}
 	}
 }
 
+func TestVerdicts(t *testing.T) {
+	testCases := []struct {
+		name            string
+		tester          Tester
+		stdout          string
+		stderr          string
+		expectedVerdict api.Verdict
+	}{
+		{
+			name:   "contains tester records every match",
+			tester: NewContainsTester(api.OutputTargetStdout, regexp.MustCompile(`o+`)),
+			stdout: "foo\nbar\nboo",
+			expectedVerdict: api.Verdict{
+				Passed: true,
+				Reason: "Command output to stdout contained `o+` on line 1 and 1 other place",
+				Spans: []api.Span{
+					{Target: api.OutputTargetStdout, Start: 1, End: 3, Line: 1, Text: "oo"},
+					{Target: api.OutputTargetStdout, Start: 9, End: 11, Line: 3, Text: "oo"},
+				},
+			},
+		},
+		{
+			name:   "contains tester records the stream that matched",
+			tester: NewContainsTester("", regexp.MustCompile(`bar`)),
+			stdout: "foo",
+			stderr: "bar",
+			expectedVerdict: api.Verdict{
+				Passed: true,
+				Reason: "Command output to stderr contained `bar` on line 1",
+				Spans:  []api.Span{{Target: api.OutputTargetStderr, Start: 0, End: 3, Line: 1, Text: "bar"}},
+			},
+		},
+		{
+			name:            "contains tester fails without a match",
+			tester:          NewContainsTester(api.OutputTargetStdout, regexp.MustCompile(`baz`)),
+			stdout:          "foo",
+			expectedVerdict: api.Verdict{Reason: "Command output to stdout did not contain `baz`"},
+		},
+		{
+			name:   "excludes tester fails with the matches",
+			tester: NewExcludesTester(api.OutputTargetStderr, regexp.MustCompile(`error`)),
+			stderr: "warning\nerror",
+			expectedVerdict: api.Verdict{
+				Reason: "Command output to stderr contained `error` on line 2",
+				Spans:  []api.Span{{Target: api.OutputTargetStderr, Start: 8, End: 13, Line: 2, Text: "error"}},
+			},
+		},
+		{
+			name:            "excludes tester passes without a match",
+			tester:          NewExcludesTester(api.OutputTargetStderr, regexp.MustCompile(`error`)),
+			stderr:          "warning",
+			expectedVerdict: api.Verdict{Passed: true, Reason: "Command output to stderr did not contain `error`"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		if expected, actual := testCase.expectedVerdict, testCase.tester.Test(testCase.stdout, testCase.stderr, testCase.stdout+"\n"+testCase.stderr); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("%s: tester did not generate correct verdict:\nexpected:\n%#v\ngot:\n%#v", testCase.name, expected, actual)
+		}
+	}
+}
+
 func TestAmbivalentTester(t *testing.T) {
 	// generate 20 random characters and supply them to the ambivalent tester, always expecting a 'true' response
 	letters := "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
@@ -105,7 +232,7 @@ func TestAmbivalentTester(t *testing.T) {
 	}
 
 	stdout, stderr := string(bytes[:20]), string(bytes[20:])
-	if !NewAmbivalentTester().Test(stdout, stderr) {
+	if !NewAmbivalentTester().Test(stdout, stderr, stdout+"\n"+stderr).Passed {
 		t.Errorf("ambivalent tester did not return true for input: stdout: %q, stder: %q", stdout, stderr)
 	}
 }
@@ -1,7 +1,10 @@
 package result
 
+import "github.com/stevekuznetsov/exec-assert/pkg/api"
+
 // Tester knows how to test an error for a condition
 type Tester interface {
-	// Test tests the result for a condition
-	Test(result error) (success bool)
+	// Test tests the result for a condition, giving a verdict that explains why the
+	// condition was or was not met
+	Test(result error) (verdict api.Verdict)
 }
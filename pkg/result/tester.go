@@ -1,5 +1,15 @@
 package result
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+	"github.com/stevekuznetsov/exec-assert/pkg/util"
+)
+
 // NewSuccessTester returns a Tester that tests if the command resulted in success
 func NewSuccessTester() Tester {
 	return &successTester{}
@@ -9,8 +19,8 @@ func NewSuccessTester() Tester {
 type successTester struct{}
 
 // Test determines if the result denotes success
-func (t *successTester) Test(result error) bool {
-	return result == nil
+func (t *successTester) Test(result error) api.Verdict {
+	return verdict(result == nil, result, "succeed")
 }
 
 // NewFailureTester returns a Tester that tests if the command resulted in failure
@@ -21,9 +31,83 @@ func NewFailureTester() Tester {
 // failureTester tests if a command resulted in failure
 type failureTester struct{}
 
-// Test determines if the result denotes failure
-func (t *failureTester) Test(result error) bool {
-	return result != nil
+// Test determines if the result denotes failure. A command that was killed for timing out did not fail on its own,
+// so a timeout does not denote failure.
+func (t *failureTester) Test(result error) api.Verdict {
+	return verdict(result != nil && !util.IsTimeoutResult(result), result, "fail")
+}
+
+// NewExitCodeTester returns a Tester that tests if the command exited with a code in the set
+func NewExitCodeTester(codes api.ExitCodes) Tester {
+	return &exitCodeTester{codes: codes}
+}
+
+// exitCodeTester tests if a command exited with a code in the set
+type exitCodeTester struct {
+	// codes is the set of exit codes the command is expected to exit with
+	codes api.ExitCodes
+}
+
+// Test determines if the result denotes an exit with a code in the set, or outside of it if the set is negated.
+// A command that did not exit on its own has no exit code and never passes.
+func (t *exitCodeTester) Test(result error) api.Verdict {
+	code, ok := util.ExitCode(result)
+	if !ok {
+		return verdict(false, result, "exit with a code")
+	}
+
+	inSet := false
+	for _, codeRange := range t.codes.Ranges {
+		if codeRange.Min <= code && code <= codeRange.Max {
+			inSet = true
+			break
+		}
+	}
+	return verdict(inSet != t.codes.Negated, result, describeExitCodes(t.codes))
+}
+
+// describeExitCodes describes what a command is expected to do to exit with a code in the set, like "exit with
+// code 0" or "exit with a code other than 1, 64-78"
+func describeExitCodes(codes api.ExitCodes) string {
+	var ranges []string
+	for _, codeRange := range codes.Ranges {
+		if codeRange.Min == codeRange.Max {
+			ranges = append(ranges, strconv.Itoa(codeRange.Min))
+		} else {
+			ranges = append(ranges, fmt.Sprintf("%d-%d", codeRange.Min, codeRange.Max))
+		}
+	}
+
+	switch {
+	case codes.Negated:
+		return fmt.Sprintf("exit with a code other than %s", strings.Join(ranges, ", "))
+	case len(ranges) == 1 && codes.Ranges[0].Min == codes.Ranges[0].Max:
+		return fmt.Sprintf("exit with code %s", ranges[0])
+	default:
+		return fmt.Sprintf("exit with one of the codes %s", strings.Join(ranges, ", "))
+	}
+}
+
+// NewSignaledTester returns a Tester that tests if the command was killed by the signal, or by any signal if it is zero
+func NewSignaledTester(signal syscall.Signal) Tester {
+	return &signaledTester{signal: signal}
+}
+
+// signaledTester tests if a command was killed by a signal
+type signaledTester struct {
+	// signal is the signal the command is expected to be killed by, or zero if any signal is expected
+	signal syscall.Signal
+}
+
+// Test determines if the result denotes that the command was killed by the expected signal
+func (t *signaledTester) Test(result error) api.Verdict {
+	expectation := "be killed by a signal"
+	if t.signal != 0 {
+		expectation = fmt.Sprintf("be killed by %s", util.SignalName(t.signal))
+	}
+
+	signal, ok := util.Signal(result)
+	return verdict(ok && (t.signal == 0 || signal == t.signal), result, expectation)
 }
 
 // NewAmbivalentTester returns a Tester that always succeeds and does not test the command result
@@ -35,6 +119,28 @@ func NewAmbivalentTester() Tester {
 type ambivalentTester struct{}
 
 // Test always succeeds and does not test the command result
-func (t *ambivalentTester) Test(result error) bool {
-	return true
+func (t *ambivalentTester) Test(result error) api.Verdict {
+	return api.Verdict{Passed: true, Reason: fmt.Sprintf("the command %s", DescribeResult(result))}
+}
+
+// verdict gives the verdict on a result, describing what happened and, if the test did not pass, what was expected to
+func verdict(passed bool, result error, expectation string) api.Verdict {
+	if passed {
+		return api.Verdict{Passed: true, Reason: fmt.Sprintf("the command %s", DescribeResult(result))}
+	}
+	return api.Verdict{Reason: fmt.Sprintf("the command %s, but was expected to %s", DescribeResult(result), expectation)}
+}
+
+// DescribeResult describes how a command ended, like "exited with code 3" or "was killed by SIGSEGV"
+func DescribeResult(result error) string {
+	if timeout, ok := result.(*util.TimeoutResult); ok {
+		return fmt.Sprintf("timed out after %.3fs and was killed", timeout.After.Seconds())
+	}
+	if code, ok := util.ExitCode(result); ok {
+		return fmt.Sprintf("exited with code %d", code)
+	}
+	if signal, ok := util.Signal(result); ok {
+		return fmt.Sprintf("was killed by %s", util.SignalName(signal))
+	}
+	return fmt.Sprintf("failed to run: %v", result)
 }
@@ -2,7 +2,14 @@ package result
 
 import (
 	"errors"
+	"fmt"
+	"os/exec"
+	"syscall"
 	"testing"
+	"time"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+	"github.com/stevekuznetsov/exec-assert/pkg/util"
 )
 
 func TestSuccessTester(t *testing.T) {
@@ -16,10 +23,15 @@ func TestSuccessTester(t *testing.T) {
 			result:         nil,
 			expectedOutput: true,
 		},
+		{
+			name:           "testing timeout result",
+			result:         util.NewTimeoutResult(1*time.Second, errors.New("signal: terminated")),
+			expectedOutput: false,
+		},
 	}
 
 	for _, testCase := range testCases {
-		if expected, actual := testCase.expectedOutput, NewSuccessTester().Test(testCase.result); expected != actual {
+		if expected, actual := testCase.expectedOutput, NewSuccessTester().Test(testCase.result).Passed; expected != actual {
 			t.Errorf("%s: success tester did not generate correct output for result %v, expected %v, got %v", testCase.name, testCase.result, expected, actual)
 		}
 	}
@@ -41,10 +53,25 @@ func TestFailureTester(t *testing.T) {
 			result:         errors.New("non-nil error"),
 			expectedOutput: true,
 		},
+		{
+			name:           "testing timeout result",
+			result:         util.NewTimeoutResult(1*time.Second, errors.New("signal: terminated")),
+			expectedOutput: false,
+		},
+		{
+			name:           "testing timeout result of a command that exited with a non-zero code when told to stop",
+			result:         util.NewTimeoutResult(1*time.Second, exitError(t, 1)),
+			expectedOutput: false,
+		},
+		{
+			name:           "testing wrapped timeout result",
+			result:         fmt.Errorf("wrapped: %w", util.NewTimeoutResult(1*time.Second, signalError(t, "TERM"))),
+			expectedOutput: false,
+		},
 	}
 
 	for _, testCase := range testCases {
-		if expected, actual := testCase.expectedOutput, NewFailureTester().Test(testCase.result); expected != actual {
+		if expected, actual := testCase.expectedOutput, NewFailureTester().Test(testCase.result).Passed; expected != actual {
 			t.Errorf("%s: failure tester did not generate correct output for result %v, expected %v, got %v", testCase.name, testCase.result, expected, actual)
 		}
 	}
@@ -69,8 +96,212 @@ func TestAmbivalentTester(t *testing.T) {
 	}
 
 	for _, testCase := range testCases {
-		if expected, actual := testCase.expectedOutput, NewAmbivalentTester().Test(testCase.result); expected != actual {
+		if expected, actual := testCase.expectedOutput, NewAmbivalentTester().Test(testCase.result).Passed; expected != actual {
 			t.Errorf("%s: ambivalent tester did not generate correct output for result %v, expected %v, got %v", testCase.name, testCase.result, expected, actual)
 		}
 	}
 }
+
+func TestExitCodeTester(t *testing.T) {
+	testCases := []struct {
+		name           string
+		codes          api.ExitCodes
+		result         error
+		expectedOutput bool
+	}{
+		{
+			name:           "nil result in set",
+			codes:          api.ExitCodes{Ranges: []api.ExitCodeRange{{Min: 0, Max: 0}}},
+			result:         nil,
+			expectedOutput: true,
+		},
+		{
+			name:           "exit code in set",
+			codes:          api.ExitCodes{Ranges: []api.ExitCodeRange{{Min: 3, Max: 3}}},
+			result:         exitError(t, 3),
+			expectedOutput: true,
+		},
+		{
+			name:           "exit code not in set",
+			codes:          api.ExitCodes{Ranges: []api.ExitCodeRange{{Min: 3, Max: 3}}},
+			result:         exitError(t, 2),
+			expectedOutput: false,
+		},
+		{
+			name:           "exit code in one of many codes",
+			codes:          api.ExitCodes{Ranges: []api.ExitCodeRange{{Min: 1, Max: 1}, {Min: 2, Max: 2}}},
+			result:         exitError(t, 2),
+			expectedOutput: true,
+		},
+		{
+			name:           "exit code in range",
+			codes:          api.ExitCodes{Ranges: []api.ExitCodeRange{{Min: 64, Max: 78}}},
+			result:         exitError(t, 70),
+			expectedOutput: true,
+		},
+		{
+			name:           "exit code outside of range",
+			codes:          api.ExitCodes{Ranges: []api.ExitCodeRange{{Min: 64, Max: 78}}},
+			result:         exitError(t, 79),
+			expectedOutput: false,
+		},
+		{
+			name:           "exit code in negated set",
+			codes:          api.ExitCodes{Ranges: []api.ExitCodeRange{{Min: 0, Max: 0}}, Negated: true},
+			result:         nil,
+			expectedOutput: false,
+		},
+		{
+			name:           "exit code outside of negated set",
+			codes:          api.ExitCodes{Ranges: []api.ExitCodeRange{{Min: 0, Max: 0}}, Negated: true},
+			result:         exitError(t, 1),
+			expectedOutput: true,
+		},
+		{
+			name:           "result without exit code",
+			codes:          api.ExitCodes{Ranges: []api.ExitCodeRange{{Min: 0, Max: 0}}, Negated: true},
+			result:         errors.New("non-nil error"),
+			expectedOutput: false,
+		},
+		{
+			name:           "timeout result",
+			codes:          api.ExitCodes{Ranges: []api.ExitCodeRange{{Min: 0, Max: 0}}, Negated: true},
+			result:         util.NewTimeoutResult(1*time.Second, exitError(t, 1)),
+			expectedOutput: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		if expected, actual := testCase.expectedOutput, NewExitCodeTester(testCase.codes).Test(testCase.result).Passed; expected != actual {
+			t.Errorf("%s: exit code tester did not generate correct output for result %v, expected %v, got %v", testCase.name, testCase.result, expected, actual)
+		}
+	}
+}
+
+// exitError runs a command that exits with the given code in order to generate a real exit error
+func exitError(t *testing.T, code int) error {
+	err := exec.Command("bash", "-c", fmt.Sprintf("exit %d", code)).Run()
+	if _, ok := err.(*exec.ExitError); !ok {
+		t.Fatalf("failed to generate exit error for code %d: %v", code, err)
+	}
+	return err
+}
+
+func TestSignaledTester(t *testing.T) {
+	testCases := []struct {
+		name           string
+		signal         syscall.Signal
+		result         error
+		expectedOutput bool
+	}{
+		{
+			name:           "nil result",
+			signal:         syscall.SIGSEGV,
+			result:         nil,
+			expectedOutput: false,
+		},
+		{
+			name:           "non-zero exit code",
+			signal:         0,
+			result:         exitError(t, 139),
+			expectedOutput: false,
+		},
+		{
+			name:           "killed by expected signal",
+			signal:         syscall.SIGSEGV,
+			result:         signalError(t, "SEGV"),
+			expectedOutput: true,
+		},
+		{
+			name:           "killed by other signal",
+			signal:         syscall.SIGSEGV,
+			result:         signalError(t, "ABRT"),
+			expectedOutput: false,
+		},
+		{
+			name:           "killed by any signal",
+			signal:         0,
+			result:         signalError(t, "ABRT"),
+			expectedOutput: true,
+		},
+		{
+			name:           "timeout result",
+			signal:         0,
+			result:         util.NewTimeoutResult(1*time.Second, signalError(t, "TERM")),
+			expectedOutput: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		if expected, actual := testCase.expectedOutput, NewSignaledTester(testCase.signal).Test(testCase.result).Passed; expected != actual {
+			t.Errorf("%s: signaled tester did not generate correct output for result %v, expected %v, got %v", testCase.name, testCase.result, expected, actual)
+		}
+	}
+}
+
+func TestVerdictReasons(t *testing.T) {
+	testCases := []struct {
+		name           string
+		tester         Tester
+		result         error
+		expectedReason string
+	}{
+		{
+			name:           "success",
+			tester:         NewSuccessTester(),
+			result:         nil,
+			expectedReason: "the command exited with code 0",
+		},
+		{
+			name:           "unexpected failure",
+			tester:         NewSuccessTester(),
+			result:         exitError(t, 2),
+			expectedReason: "the command exited with code 2, but was expected to succeed",
+		},
+		{
+			name:           "unexpected success",
+			tester:         NewFailureTester(),
+			result:         nil,
+			expectedReason: "the command exited with code 0, but was expected to fail",
+		},
+		{
+			name:           "exit code outside of the expected codes",
+			tester:         NewExitCodeTester(api.ExitCodes{Ranges: []api.ExitCodeRange{{Min: 1, Max: 1}, {Min: 64, Max: 78}}}),
+			result:         exitError(t, 2),
+			expectedReason: "the command exited with code 2, but was expected to exit with one of the codes 1, 64-78",
+		},
+		{
+			name:           "unexpected signal",
+			tester:         NewSignaledTester(syscall.SIGKILL),
+			result:         signalError(t, "TERM"),
+			expectedReason: "the command was killed by SIGTERM, but was expected to be killed by SIGKILL",
+		},
+		{
+			name:           "timeout",
+			tester:         NewSuccessTester(),
+			result:         util.NewTimeoutResult(1500*time.Millisecond, signalError(t, "TERM")),
+			expectedReason: "the command timed out after 1.500s and was killed, but was expected to succeed",
+		},
+		{
+			name:           "failure to run",
+			tester:         NewSuccessTester(),
+			result:         errors.New("no such file"),
+			expectedReason: "the command failed to run: no such file, but was expected to succeed",
+		},
+	}
+
+	for _, testCase := range testCases {
+		if expected, actual := testCase.expectedReason, testCase.tester.Test(testCase.result).Reason; expected != actual {
+			t.Errorf("%s: tester did not generate correct reason: expected %q, got %q", testCase.name, expected, actual)
+		}
+	}
+}
+
+// signalError runs a command that is killed by the given signal in order to generate a real exit error
+func signalError(t *testing.T, signal string) error {
+	err := exec.Command("bash", "-c", fmt.Sprintf("kill -%s $$", signal)).Run()
+	if _, ok := err.(*exec.ExitError); !ok {
+		t.Fatalf("failed to generate exit error for signal %s: %v", signal, err)
+	}
+	return err
+}
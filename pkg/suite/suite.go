@@ -0,0 +1,222 @@
+package suite
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+	"github.com/stevekuznetsov/exec-assert/pkg/util"
+)
+
+// Suite is a list of tests that are run together
+type Suite struct {
+	// Tests are the tests in the suite
+	Tests []Test `json:"tests"`
+}
+
+// Test is one test in a suite, mapping onto an api.ExecutionAssertionConfig. Fields that are not set take
+// their value from the defaults given when the test is converted to a configuration.
+type Test struct {
+	// Name is the optional name of the test
+	Name string `json:"name,omitempty"`
+
+	// Command is the command to execute
+	Command string `json:"command"`
+
+	// Execute is the execution strategy to use, like `once`, `until`, `backoff` or `consistently`
+	Execute string `json:"execute,omitempty"`
+
+	// Result is the result assertion to make, like `success` or `exit-code=3`
+	Result string `json:"result,omitempty"`
+
+	// Output are the output assertions to make
+	Output []OutputAssertion `json:"output,omitempty"`
+
+	// JSON are queries that must hold on the output to stdout parsed as JSON, like `.items | length >= 3`
+	JSON []string `json:"json,omitempty"`
+
+	// Golden are the golden files the output must match exactly
+	Golden []GoldenFile `json:"golden,omitempty"`
+
+	// Timeout is the timeout for the whole execution
+	Timeout *Duration `json:"timeout,omitempty"`
+
+	// Interval is the interval between executions when executing until assertions are met, or the first
+	// interval when backing off
+	Interval *Duration `json:"interval,omitempty"`
+
+	// Multiplier is the factor by which the interval grows after each execution when backing off
+	Multiplier float64 `json:"multiplier,omitempty"`
+
+	// MaxInterval is the longest interval between executions when backing off
+	MaxInterval *Duration `json:"maxInterval,omitempty"`
+
+	// Jitter is the fraction of each interval by which it is randomly changed when backing off
+	Jitter float64 `json:"jitter,omitempty"`
+
+	// Consecutive is how many back-to-back executions must meet the assertions when executing until they are met
+	Consecutive int `json:"consecutive,omitempty"`
+
+	// AttemptTimeout is the timeout for any one execution of the command
+	AttemptTimeout *Duration `json:"attemptTimeout,omitempty"`
+
+	// GracePeriod is how long a command that timed out has to exit after SIGTERM before it is sent SIGKILL
+	GracePeriod *Duration `json:"gracePeriod,omitempty"`
+
+	// Verbose determines if output to stdout and stderr should be shown always
+	Verbose bool `json:"verbose,omitempty"`
+
+	// Context is how many lines of output to show around each match instead of the whole output
+	Context int `json:"context,omitempty"`
+}
+
+// OutputAssertion is one assertion about the output of a test
+type OutputAssertion struct {
+	// Assertion is the assertion to make, like `contains`, `excludes`, `contains>=2`, `lines=3`, `empty` or `in-order`
+	Assertion string `json:"assertion"`
+
+	// Target is the stream the assertion targets, like `stdout`, `stderr` or `combined`; both stdout and
+	// stderr are targeted if it is not set
+	Target string `json:"target,omitempty"`
+
+	// Test is what the output is tested with
+	Test string `json:"test,omitempty"`
+
+	// Tests are what the output is tested with, in order, for an `in-order` assertion
+	Tests []string `json:"tests,omitempty"`
+
+	// Match is how the tests are matched, like `literal`, `glob` or `regex`
+	Match string `json:"match,omitempty"`
+}
+
+// GoldenFile is a golden file that the output of a test must match exactly
+type GoldenFile struct {
+	// Target is the stream that must match the golden file; stdout is matched if it is not set
+	Target string `json:"target,omitempty"`
+
+	// Path is the path to the golden file
+	Path string `json:"path"`
+}
+
+// Duration is a duration that is written like `1m30s`
+type Duration struct {
+	time.Duration
+}
+
+// UnmarshalJSON parses a duration like `1m30s`
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("duration must be a string like \"1m30s\": %v", err)
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	d.Duration = duration
+	return nil
+}
+
+// MarshalJSON writes a duration like `1m30s`
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// Load loads a suite from a JSON file
+func Load(path string) (*Suite, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open suite: %v", err)
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(file)
+	decoder.DisallowUnknownFields()
+
+	var suite Suite
+	if err := decoder.Decode(&suite); err != nil {
+		return nil, fmt.Errorf("failed to parse suite %s: %v", path, err)
+	}
+	return &suite, nil
+}
+
+// Config converts the test to a configuration, taking the value of any field that is not set from the defaults;
+// only the assertions of the test are made, not any in the defaults
+func (t Test) Config(defaults api.ExecutionAssertionConfig) (api.ExecutionAssertionConfig, error) {
+	if math.IsNaN(t.Multiplier) || math.IsInf(t.Multiplier, 0) {
+		return api.ExecutionAssertionConfig{}, fmt.Errorf("multiplier must be a finite number, got %v", t.Multiplier)
+	}
+	if math.IsNaN(t.Jitter) || math.IsInf(t.Jitter, 0) {
+		return api.ExecutionAssertionConfig{}, fmt.Errorf("jitter must be a finite number, got %v", t.Jitter)
+	}
+
+	config := defaults
+	config.Command = t.Command
+	config.Name = t.Name
+	config.Verbose = defaults.Verbose || t.Verbose
+
+	if t.Context > 0 {
+		config.Context = t.Context
+	}
+	if len(t.Execute) > 0 {
+		config.ExecutionStrategy = t.Execute
+	}
+	if len(t.Result) > 0 {
+		config.ResultAssertion = t.Result
+	}
+	if t.Timeout != nil {
+		config.Timeout = t.Timeout.Duration
+	} else if config.ExecutionStrategy == api.ExecutionStrategyOnce {
+		// the default timeout bounds repeated executions; a command executed once runs for as long as it needs to
+		config.Timeout = 0
+	}
+	if t.Interval != nil {
+		config.Interval = t.Interval.Duration
+	}
+	if t.Multiplier > 0 {
+		config.Multiplier = t.Multiplier
+	}
+	if t.MaxInterval != nil {
+		config.MaxInterval = t.MaxInterval.Duration
+	}
+	if t.Jitter > 0 {
+		config.Jitter = t.Jitter
+	}
+	if t.Consecutive > 0 {
+		config.Consecutive = t.Consecutive
+	}
+	if t.AttemptTimeout != nil {
+		config.AttemptTimeout = t.AttemptTimeout.Duration
+	}
+	if t.GracePeriod != nil {
+		config.GracePeriod = t.GracePeriod.Duration
+	}
+
+	config.Assertions = nil
+	for _, assertion := range t.Output {
+		kind, count, err := util.ParseOutputAssertion(assertion.Assertion)
+		if err != nil {
+			return api.ExecutionAssertionConfig{}, err
+		}
+		config.Assertions = append(config.Assertions, api.Assertion{
+			Kind:    kind,
+			Target:  api.OutputTarget(assertion.Target),
+			Pattern: assertion.Test,
+			Options: api.AssertionOptions{Match: api.MatchMode(assertion.Match), Count: count, Sequence: assertion.Tests},
+		})
+	}
+
+	for _, goldenFile := range t.Golden {
+		config.Assertions = append(config.Assertions, api.Assertion{Kind: api.OutputAssertionGolden, Target: api.OutputTarget(goldenFile.Target), Pattern: goldenFile.Path})
+	}
+
+	for _, query := range t.JSON {
+		config.Assertions = append(config.Assertions, api.Assertion{Kind: api.OutputAssertionJSON, Pattern: query})
+	}
+
+	return config, nil
+}
@@ -0,0 +1,161 @@
+package suite
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+)
+
+func TestConfig(t *testing.T) {
+	defaults := api.ExecutionAssertionConfig{
+		ExecutionStrategy: "once",
+		ResultAssertion:   "success",
+		Assertions:        []api.Assertion{{Kind: api.OutputAssertionAmbivalent}},
+		Timeout:           time.Minute,
+		Interval:          time.Second,
+		GracePeriod:       5 * time.Second,
+	}
+
+	testCases := []struct {
+		name           string
+		test           string
+		expectedConfig api.ExecutionAssertionConfig
+		expectedError  bool
+	}{
+		{
+			name: "test without assertions takes defaults",
+			test: `{"command": "pwd"}`,
+			expectedConfig: api.ExecutionAssertionConfig{
+				Command:           "pwd",
+				ExecutionStrategy: "once",
+				ResultAssertion:   "success",
+				Interval:          time.Second,
+				GracePeriod:       5 * time.Second,
+			},
+		},
+		{
+			name: "test executing once with a timeout",
+			test: `{"command": "pwd", "timeout": "10s"}`,
+			expectedConfig: api.ExecutionAssertionConfig{
+				Command:           "pwd",
+				ExecutionStrategy: "once",
+				ResultAssertion:   "success",
+				Timeout:           10 * time.Second,
+				Interval:          time.Second,
+				GracePeriod:       5 * time.Second,
+			},
+		},
+		{
+			name: "test executing until takes the default timeout",
+			test: `{"command": "pwd", "execute": "until"}`,
+			expectedConfig: api.ExecutionAssertionConfig{
+				Command:           "pwd",
+				ExecutionStrategy: "until",
+				ResultAssertion:   "success",
+				Timeout:           time.Minute,
+				Interval:          time.Second,
+				GracePeriod:       5 * time.Second,
+			},
+		},
+		{
+			name: "test with every field set",
+			test: `{
+				"name": "TestEverything",
+				"command": "./server",
+				"execute": "until",
+				"result": "exit-code=3",
+				"output": [
+					{"assertion": "contains", "target": "stdout", "test": "[ready]", "match": "literal"},
+					{"assertion": "in-order", "tests": ["a", "b"]},
+					{"assertion": "empty", "target": "stderr"},
+					{"assertion": "contains>=2", "test": "x"}
+				],
+				"json": [".items | length >= 3", ".kind == \"List\""],
+				"golden": [{"path": "out.golden"}, {"target": "stderr", "path": "err.golden"}],
+				"timeout": "10s",
+				"interval": "500ms",
+				"attemptTimeout": "2s",
+				"gracePeriod": "1s",
+				"verbose": true,
+				"context": 3
+			}`,
+			expectedConfig: api.ExecutionAssertionConfig{
+				Name:              "TestEverything",
+				Command:           "./server",
+				ExecutionStrategy: "until",
+				ResultAssertion:   "exit-code=3",
+				Assertions: []api.Assertion{
+					{Kind: api.OutputAssertionContains, Target: api.OutputTargetStdout, Pattern: "[ready]", Options: api.AssertionOptions{Match: api.MatchModeLiteral}},
+					{Kind: api.OutputAssertionInOrder, Options: api.AssertionOptions{Sequence: []string{"a", "b"}}},
+					{Kind: api.OutputAssertionLineCount, Target: api.OutputTargetStderr, Options: api.AssertionOptions{Count: api.CountComparison{Operator: api.CountOperatorExactly, Count: 0}}},
+					{Kind: api.OutputAssertionMatchCount, Pattern: "x", Options: api.AssertionOptions{Count: api.CountComparison{Operator: api.CountOperatorAtLeast, Count: 2}}},
+					{Kind: api.OutputAssertionGolden, Pattern: "out.golden"},
+					{Kind: api.OutputAssertionGolden, Target: api.OutputTargetStderr, Pattern: "err.golden"},
+					{Kind: api.OutputAssertionJSON, Pattern: ".items | length >= 3"},
+					{Kind: api.OutputAssertionJSON, Pattern: `.kind == "List"`},
+				},
+				Timeout:        10 * time.Second,
+				Interval:       500 * time.Millisecond,
+				AttemptTimeout: 2 * time.Second,
+				GracePeriod:    time.Second,
+				Verbose:        true,
+				Context:        3,
+			},
+		},
+		{
+			name: "test backing off",
+			test: `{"command": "pwd", "execute": "backoff", "interval": "100ms", "multiplier": 1.5, "maxInterval": "10s", "jitter": 0.2, "consecutive": 3}`,
+			expectedConfig: api.ExecutionAssertionConfig{
+				Command:           "pwd",
+				ExecutionStrategy: "backoff",
+				ResultAssertion:   "success",
+				Timeout:           time.Minute,
+				Interval:          100 * time.Millisecond,
+				Multiplier:        1.5,
+				MaxInterval:       10 * time.Second,
+				Jitter:            0.2,
+				Consecutive:       3,
+				GracePeriod:       5 * time.Second,
+			},
+		},
+		{
+			name:          "test with an unrecognized output assertion",
+			test:          `{"command": "pwd", "output": [{"assertion": "contains>x"}]}`,
+			expectedError: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		var test Test
+		if err := json.Unmarshal([]byte(testCase.test), &test); err != nil {
+			t.Errorf("%s: failed to parse test: %v", testCase.name, err)
+			continue
+		}
+
+		config, err := test.Config(defaults)
+		if expected, actual := testCase.expectedError, err != nil; expected != actual {
+			t.Errorf("%s: expected error %v, got %v", testCase.name, expected, err)
+			continue
+		}
+		if expected, actual := testCase.expectedConfig, config; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("%s: did not convert test to correct config:\nexpected: %#v\ngot:      %#v", testCase.name, expected, actual)
+		}
+	}
+}
+
+func TestDurationUnmarshal(t *testing.T) {
+	var duration Duration
+	if err := json.Unmarshal([]byte(`"1m30s"`), &duration); err != nil {
+		t.Fatalf("failed to parse duration: %v", err)
+	}
+	if expected, actual := 90*time.Second, duration.Duration; expected != actual {
+		t.Errorf("did not parse duration correctly: expected %v, got %v", expected, actual)
+	}
+
+	if err := json.Unmarshal([]byte(`90`), &duration); err == nil {
+		t.Errorf("expected an error parsing a duration that is not a string")
+	}
+}
@@ -0,0 +1,79 @@
+package summarizer
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+	"github.com/stevekuznetsov/exec-assert/pkg/util"
+)
+
+// ConsistentlyDeclarerSummarizer knows how to interpret test data and config from a test that runs the command
+// repeatedly, expecting the assertions to hold every time
+type ConsistentlyDeclarerSummarizer struct {
+	// Renderer renders the parts of the summary that stand out and highlights matches in the output of the last execution
+	Renderer Renderer
+
+	// declaration stores the declaration so it can be used by the summarizer
+	declaration string
+
+	// resultAssertion and assertions store the assertions so that their verdicts on each execution can be described
+	resultAssertion string
+	assertions      []api.Assertion
+}
+
+var _ Declarer = &ConsistentlyDeclarerSummarizer{}
+var _ Summarizer = &ConsistentlyDeclarerSummarizer{}
+
+func (s *ConsistentlyDeclarerSummarizer) Declare(config api.ExecutionAssertionConfig) string {
+	var declaration bytes.Buffer
+
+	if len(config.Name) > 0 {
+		declaration.WriteString(fmt.Sprintf("%s: ", config.Name))
+	}
+
+	declaration.WriteString(fmt.Sprintf("executing %#q every %.3fs for %.3fs", config.Command, config.Interval.Seconds(), config.Timeout.Seconds()))
+
+	assertionDescription := describeAssertions(", expecting", config.ResultAssertion, config.Assertions)
+	if len(assertionDescription) > 0 {
+		declaration.WriteString(assertionDescription)
+		declaration.WriteString(" every time")
+	}
+
+	declaration.WriteString("\n")
+
+	s.declaration = declaration.String()
+	s.resultAssertion, s.assertions = config.ResultAssertion, config.Assertions
+	return s.declaration
+}
+
+// Summarize summarizes test data assuming that the test ran the command repeatedly, stopping at the first execution
+// that broke the assertions, so that the last execution is the one that broke them if any did
+func (s *ConsistentlyDeclarerSummarizer) Summarize(results api.ExecutionAssertionResults, verbose bool) string {
+	var summary bytes.Buffer
+
+	if util.Passed(results) {
+		summary.WriteString(fmt.Sprintf("%s after %s: %s", s.Renderer.status(true), s.Renderer.duration(results.Duration), s.declaration))
+	} else {
+		// we do not want the trailing newline on the declaration in this case, as we have more to put on this line
+		declaration := strings.TrimRight(s.declaration, "\n")
+		summary.WriteString(fmt.Sprintf("%s after %s: %s: ", s.Renderer.status(false), s.Renderer.duration(results.Duration), declaration))
+		if len(results.Attempts) > 0 {
+			broken := results.Attempts[len(results.Attempts)-1]
+			summary.WriteString(fmt.Sprintf("attempt %d at +%s broke the assertions: ", len(results.Attempts), s.Renderer.duration(broken.Start)))
+		}
+		summary.WriteString(fmt.Sprintf("%s\n", strings.Join(describeFailures(results, lastResult(results.Result)), "; ")))
+		summary.WriteString(describeOutputExplanations(results.OutputVerdicts))
+	}
+
+	if !(util.Passed(results)) || verbose {
+		if len(results.Attempts) > 0 {
+			summary.WriteString(s.Renderer.header("Attempts:") + "\n")
+			summary.WriteString(describeAttempts(results.Attempts, s.resultAssertion, s.assertions, s.Renderer))
+		}
+		summary.WriteString(describeOutput(results, s.Renderer))
+	}
+
+	return summary.String()
+}
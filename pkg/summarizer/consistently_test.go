@@ -0,0 +1,150 @@
+package summarizer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+	"github.com/stevekuznetsov/exec-assert/pkg/util"
+)
+
+func TestConsistentlyDeclare(t *testing.T) {
+	testCases := []struct {
+		name                string
+		config              api.ExecutionAssertionConfig
+		expectedDeclaration string
+	}{
+		{
+			name: "expecting success",
+			config: api.ExecutionAssertionConfig{
+				Command:           "command",
+				ExecutionStrategy: "consistently",
+				ResultAssertion:   "success",
+				Timeout:           30 * time.Second,
+				Interval:          time.Second,
+			},
+			expectedDeclaration: "executing `command` every 1.000s for 30.000s, expecting success every time\n",
+		},
+		{
+			name: "expecting output to be excluded",
+			config: api.ExecutionAssertionConfig{
+				Command:           "command",
+				ExecutionStrategy: "consistently",
+				ResultAssertion:   "ambivalent",
+				Assertions:        []api.Assertion{{Kind: api.OutputAssertionExcludes, Pattern: "created"}},
+				Timeout:           30 * time.Second,
+				Interval:          time.Second,
+				Name:              "TestNeverCreated",
+			},
+			expectedDeclaration: "TestNeverCreated: executing `command` every 1.000s for 30.000s, expecting output that doesn't contain `created` every time\n",
+		},
+	}
+
+	for _, testCase := range testCases {
+		declarer := ConsistentlyDeclarerSummarizer{}
+		if expected, actual := testCase.expectedDeclaration, declarer.Declare(testCase.config); expected != actual {
+			t.Errorf("%s: consistently declarer did not create correct declaration for config:\nexpected:\n%q\ngot:\n%q", testCase.name, expected, actual)
+		}
+	}
+}
+
+func TestConsistentlySummarize(t *testing.T) {
+	testCases := []struct {
+		name            string
+		result          api.ExecutionAssertionResults
+		verbose         bool
+		expectedSummary string
+	}{
+		{
+			name: "success",
+			result: api.ExecutionAssertionResults{
+				Duration:        3 * time.Second,
+				Result:          util.NewCompoundResult([]error{nil, nil}),
+				ResultAssertion: true,
+				OutputAssertion: true,
+				Attempts: []api.Attempt{
+					{Start: 0, Duration: 10 * time.Millisecond, ResultVerdict: api.Verdict{Passed: true}},
+					{Start: time.Second, Duration: 10 * time.Millisecond, ResultVerdict: api.Verdict{Passed: true}},
+				},
+			},
+			expectedSummary: "SUCCESS after 3.000s: declaration\n",
+		},
+		{
+			name: "verbose success",
+			result: api.ExecutionAssertionResults{
+				Duration:        3 * time.Second,
+				Result:          util.NewCompoundResult([]error{nil, nil}),
+				ResultAssertion: true,
+				Stdout:          "200",
+				OutputAssertion: true,
+				Attempts: []api.Attempt{
+					{Start: 0, Duration: 10 * time.Millisecond, Stdout: "200", ResultVerdict: api.Verdict{Passed: true}},
+					{Start: time.Second, Duration: 10 * time.Millisecond, Stdout: "200", ResultVerdict: api.Verdict{Passed: true}},
+				},
+			},
+			verbose: true,
+			expectedSummary: `SUCCESS after 3.000s: declaration
+Attempts:
+attempts 1-2 at +0.000s to +1.000s: exit 0 in 0.010s, success: yes
+Command output to stdout:
+200
+Command did not output to stderr.
+`,
+		},
+		{
+			name: "result assertion broken",
+			result: api.ExecutionAssertionResults{
+				Duration:        1200 * time.Millisecond,
+				Result:          util.NewCompoundResult([]error{nil, exitError(t, 7)}),
+				ResultAssertion: false,
+				Stderr:          "connection refused",
+				OutputAssertion: true,
+				Attempts: []api.Attempt{
+					{Start: 0, Duration: 10 * time.Millisecond, ResultVerdict: api.Verdict{Passed: true}},
+					{Start: time.Second, Duration: 200 * time.Millisecond, Result: exitError(t, 7), Stderr: "connection refused"},
+				},
+			},
+			expectedSummary: `FAILURE after 1.200s: declaration: attempt 2 at +1.000s broke the assertions: the execution result assertion failed (exit code 7)
+Attempts:
+attempt 1 at +0.000s: exit 0 in 0.010s, success: yes
+attempt 2 at +1.000s: exit 7 in 0.200s, success: no
+Command did not output to stdout.
+Command output to stderr:
+connection refused
+`,
+		},
+		{
+			name: "output assertion broken",
+			result: api.ExecutionAssertionResults{
+				Duration:        1 * time.Second,
+				Result:          util.NewCompoundResult([]error{nil}),
+				ResultAssertion: true,
+				Stdout:          "created",
+				OutputAssertion: false,
+				OutputVerdicts: []api.Verdict{{
+					Reason: "Command output to stdout contained `created` on line 1",
+					Spans:  []api.Span{{Target: api.OutputTargetStdout, Start: 0, End: 7, Line: 1, Text: "created"}},
+				}},
+				Attempts: []api.Attempt{
+					{Start: 0, Duration: 10 * time.Millisecond, Stdout: "created", ResultVerdict: api.Verdict{Passed: true}},
+				},
+			},
+			expectedSummary: `FAILURE after 1.000s: declaration: attempt 1 at +0.000s broke the assertions: the execution output assertion(s) failed
+Command output to stdout contained ` + "`created`" + ` on line 1
+Attempts:
+attempt 1 at +0.000s: exit 0 in 0.010s, success: yes
+Command output to stdout:
+>>>created<<<
+Command did not output to stderr.
+`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		// initialize a summarizer with some declaration ending in a newline - we expect this from a properly functioning declarer
+		summarizer := ConsistentlyDeclarerSummarizer{declaration: "declaration\n", resultAssertion: "success"}
+		if expected, actual := testCase.expectedSummary, summarizer.Summarize(testCase.result, testCase.verbose); expected != actual {
+			t.Errorf("%s: consistently summarizer did not create correct summary for config:\nexpected:\n%q\ngot:\n%q", testCase.name, expected, actual)
+		}
+	}
+}
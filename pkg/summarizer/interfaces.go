@@ -13,3 +13,9 @@ type Summarizer interface {
 	// Summarize summarizes data generated during a test
 	Summarize(data api.ExecutionAssertionResults, verbose bool) (summary string)
 }
+
+// Reporter knows how to report on a test, declaring it before it runs and summarizing it after
+type Reporter interface {
+	Declarer
+	Summarizer
+}
@@ -0,0 +1,149 @@
+package summarizer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+	"github.com/stevekuznetsov/exec-assert/pkg/util"
+)
+
+// JSONReporter reports on a test with one line of JSON following the versioned report schema, so
+// that the reports of many tests make up JSON Lines
+type JSONReporter struct {
+	// config stores the config so it can be reported with the results
+	config api.ExecutionAssertionConfig
+}
+
+var _ Reporter = &JSONReporter{}
+
+// Declare stores the config of the test; nothing is reported until the test has run
+func (r *JSONReporter) Declare(config api.ExecutionAssertionConfig) string {
+	r.config = config
+	return ""
+}
+
+// Summarize reports the config and results of the test, always including the output of the command
+func (r *JSONReporter) Summarize(results api.ExecutionAssertionResults, verbose bool) string {
+	report := api.Report{
+		SchemaVersion: api.ReportSchemaVersion,
+		Kind:          api.ReportKindTest,
+		Name:          r.config.Name,
+		Passed:        util.Passed(results),
+		Config: &api.ReportConfig{
+			Command:        r.config.Command,
+			Execute:        r.config.ExecutionStrategy,
+			Timeout:        r.config.Timeout.Seconds(),
+			Interval:       r.config.Interval.Seconds(),
+			AttemptTimeout: r.config.AttemptTimeout.Seconds(),
+			GracePeriod:    r.config.GracePeriod.Seconds(),
+		},
+		Duration:   results.Duration.Seconds(),
+		Attempts:   1,
+		Result:     api.ReportResult{Assertion: r.config.ResultAssertion, Passed: results.ResultAssertion, Reason: results.ResultVerdict.Reason},
+		Assertions: []api.ReportAssertion{},
+		Stdout:     results.Stdout,
+		Stderr:     results.Stderr,
+		Combined:   []api.ReportLine{},
+	}
+
+	if len(results.Attempts) > 0 {
+		report.Attempts = len(results.Attempts)
+	}
+
+	if r.config.Consecutive > 1 {
+		report.Config.Consecutive = r.config.Consecutive
+	}
+	if results.ConsecutiveVerdict != nil {
+		longest, _ := util.Streaks(results.Attempts)
+		report.Consecutive = &api.ReportConsecutive{Required: r.config.Consecutive, Longest: longest, Passed: results.ConsecutiveVerdict.Passed, Reason: results.ConsecutiveVerdict.Reason}
+	}
+	if r.config.ExecutionStrategy == api.ExecutionStrategyBackoff {
+		report.Config.Multiplier = r.config.Multiplier
+		report.Config.MaxInterval = r.config.MaxInterval.Seconds()
+		report.Config.Jitter = r.config.Jitter
+	}
+
+	lastResult := lastResult(results.Result)
+	if code, ok := util.ExitCode(lastResult); ok {
+		report.ExitCode = &code
+	}
+	if signal, ok := util.Signal(lastResult); ok {
+		report.Signal = util.SignalName(signal)
+	}
+	report.TimedOut = util.IsTimeoutResult(lastResult)
+
+	for i, assertion := range r.config.Assertions {
+		reported := api.ReportAssertion{
+			Kind:     assertion.Kind,
+			Target:   assertion.Target,
+			Pattern:  assertion.Pattern,
+			Match:    assertion.Options.Match,
+			Sequence: assertion.Options.Sequence,
+		}
+		if i < len(results.OutputVerdicts) {
+			verdict := results.OutputVerdicts[i]
+			reported.Passed, reported.Reason = verdict.Passed, verdict.Reason
+			for _, span := range verdict.Spans {
+				reported.Spans = append(reported.Spans, api.ReportSpan{Target: span.Target, Start: span.Start, End: span.End, Line: span.Line, Text: span.Text})
+			}
+		}
+		if assertion.Kind == api.OutputAssertionMatchCount || assertion.Kind == api.OutputAssertionLineCount {
+			reported.Count = &api.ReportCount{Operator: assertion.Options.Count.Operator, Count: assertion.Options.Count.Count}
+		}
+		report.Assertions = append(report.Assertions, reported)
+	}
+
+	for _, line := range results.Combined {
+		report.Combined = append(report.Combined, api.ReportLine{Stream: line.Stream, Offset: line.Offset.Seconds(), Text: line.Text})
+	}
+
+	if r.config.ExecutionStrategy == api.ExecutionStrategyOnce {
+		// a single execution is already described by the rest of the report
+		return r.format(report)
+	}
+	for _, attempt := range results.Attempts {
+		reported := api.ReportAttempt{
+			Start:      attempt.Start.Seconds(),
+			Duration:   attempt.Duration.Seconds(),
+			TimedOut:   util.IsTimeoutResult(attempt.Result),
+			Result:     attempt.ResultVerdict.Passed,
+			Assertions: []bool{},
+		}
+		if code, ok := util.ExitCode(attempt.Result); ok {
+			reported.ExitCode = &code
+		}
+		if signal, ok := util.Signal(attempt.Result); ok {
+			reported.Signal = util.SignalName(signal)
+		}
+		for _, verdict := range attempt.OutputVerdicts {
+			reported.Assertions = append(reported.Assertions, verdict.Passed)
+		}
+		report.Timeline = append(report.Timeline, reported)
+	}
+
+	return r.format(report)
+}
+
+// format formats the report of the test, or if it cannot be encoded, a report of the test holding only why
+func (r *JSONReporter) format(report api.Report) string {
+	formatted, err := FormatJSON(report)
+	if err != nil {
+		// a report without any numbers from the configuration always encodes
+		formatted, _ = FormatJSON(api.Report{SchemaVersion: report.SchemaVersion, Kind: report.Kind, Name: report.Name, Passed: report.Passed, Error: err.Error()})
+	}
+	return formatted
+}
+
+// FormatJSON formats a report as one line of JSON
+func FormatJSON(report interface{}) (string, error) {
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	// commands and output are more readable without escaping characters like `>` and `&`
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(report); err != nil {
+		return "", fmt.Errorf("failed to encode report: %v", err)
+	}
+	return data.String(), nil
+}
@@ -0,0 +1,147 @@
+package summarizer
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+	"github.com/stevekuznetsov/exec-assert/pkg/util"
+)
+
+func TestJSONReporter(t *testing.T) {
+	config := api.ExecutionAssertionConfig{
+		Command:           "echo a && echo b >&2",
+		ExecutionStrategy: "once",
+		ResultAssertion:   "success",
+		Assertions: []api.Assertion{
+			{Kind: api.OutputAssertionContains, Target: api.OutputTargetStdout, Pattern: "a", Options: api.AssertionOptions{Match: api.MatchModeLiteral}},
+			{Kind: api.OutputAssertionLineCount, Target: api.OutputTargetStderr, Options: api.AssertionOptions{Count: api.CountComparison{Operator: api.CountOperatorAtLeast, Count: 2}}},
+		},
+		Timeout:     time.Minute,
+		Interval:    200 * time.Millisecond,
+		GracePeriod: 5 * time.Second,
+		Name:        "TestName",
+	}
+
+	testCases := []struct {
+		name           string
+		strategy       string
+		consecutive    int
+		result         api.ExecutionAssertionResults
+		expectedReport string
+	}{
+		{
+			name: "success with output",
+			result: api.ExecutionAssertionResults{
+				Duration:        1500 * time.Millisecond,
+				ResultAssertion: true,
+				Stdout:          "a",
+				Stderr:          "b",
+				Combined: []api.OutputLine{
+					{Stream: api.OutputStreamStdout, Offset: 1 * time.Millisecond, Text: "a"},
+					{Stream: api.OutputStreamStderr, Offset: 2 * time.Millisecond, Text: "b"},
+				},
+				ResultVerdict:   api.Verdict{Passed: true, Reason: "the command exited with code 0"},
+				OutputAssertion: true,
+				OutputVerdicts: []api.Verdict{
+					{Passed: true, Reason: "Command output to stdout contained `a` on line 1", Spans: []api.Span{{Target: api.OutputTargetStdout, Start: 0, End: 1, Line: 1, Text: "a"}}},
+					{Passed: true, Reason: "Command output to stderr had 1 lines"},
+				},
+			},
+			expectedReport: `{"schemaVersion":"exec-assert/v1","kind":"test","name":"TestName","passed":true,"config":{"command":"echo a && echo b >&2","execute":"once","timeout":60,"interval":0.2,"attemptTimeout":0,"gracePeriod":5},"duration":1.5,"attempts":1,"exitCode":0,"timedOut":false,"result":{"assertion":"success","passed":true,"reason":"the command exited with code 0"},"assertions":[{"kind":"contains","target":"stdout","pattern":"a","match":"literal","passed":true,"reason":"Command output to stdout contained ` + "`a`" + ` on line 1","spans":[{"target":"stdout","start":0,"end":1,"line":1,"text":"a"}]},{"kind":"line-count","target":"stderr","count":{"operator":">=","count":2},"passed":true,"reason":"Command output to stderr had 1 lines"}],"stdout":"a","stderr":"b","combined":[{"stream":"stdout","offset":0.001,"text":"a"},{"stream":"stderr","offset":0.002,"text":"b"}]}
+`,
+		},
+		{
+			name: "failure of one output assertion",
+			result: api.ExecutionAssertionResults{
+				Duration:        1 * time.Second,
+				Result:          exitError(t, 3),
+				ResultAssertion: false,
+				ResultVerdict:   api.Verdict{Reason: "the command exited with code 3, but was expected to succeed"},
+				OutputAssertion: false,
+				OutputVerdicts:  []api.Verdict{{Passed: true}, {Reason: "Command output to stderr had 0 lines, expected at least 2"}},
+			},
+			expectedReport: `{"schemaVersion":"exec-assert/v1","kind":"test","name":"TestName","passed":false,"config":{"command":"echo a && echo b >&2","execute":"once","timeout":60,"interval":0.2,"attemptTimeout":0,"gracePeriod":5},"duration":1,"attempts":1,"exitCode":3,"timedOut":false,"result":{"assertion":"success","passed":false,"reason":"the command exited with code 3, but was expected to succeed"},"assertions":[{"kind":"contains","target":"stdout","pattern":"a","match":"literal","passed":true},{"kind":"line-count","target":"stderr","count":{"operator":">=","count":2},"passed":false,"reason":"Command output to stderr had 0 lines, expected at least 2"}],"stdout":"","stderr":"","combined":[]}
+`,
+		},
+		{
+			name:     "many attempts, the last of which was signaled",
+			strategy: api.ExecutionStrategyUntil,
+			result: api.ExecutionAssertionResults{
+				Duration:        3 * time.Second,
+				Result:          util.NewCompoundResult([]error{exitError(t, 1), signalError(t, "SIGSEGV")}),
+				ResultAssertion: false,
+				Stdout:          "last",
+				OutputAssertion: true,
+				OutputVerdicts:  []api.Verdict{{Passed: true}, {Passed: true}},
+				Attempts: []api.Attempt{
+					{Start: 0, Duration: 1 * time.Second, Result: exitError(t, 1), Stdout: "first", OutputVerdicts: []api.Verdict{{Passed: false}, {Passed: true}}},
+					{Start: 1500 * time.Millisecond, Duration: 1500 * time.Millisecond, Result: signalError(t, "SIGSEGV"), Stdout: "last", OutputVerdicts: []api.Verdict{{Passed: true}, {Passed: true}}},
+				},
+			},
+			expectedReport: `{"schemaVersion":"exec-assert/v1","kind":"test","name":"TestName","passed":false,"config":{"command":"echo a && echo b >&2","execute":"until","timeout":60,"interval":0.2,"attemptTimeout":0,"gracePeriod":5},"duration":3,"attempts":2,"exitCode":null,"signal":"SIGSEGV","timedOut":false,"result":{"assertion":"success","passed":false,"reason":""},"assertions":[{"kind":"contains","target":"stdout","pattern":"a","match":"literal","passed":true},{"kind":"line-count","target":"stderr","count":{"operator":">=","count":2},"passed":true}],"stdout":"last","stderr":"","combined":[],"timeline":[{"start":0,"duration":1,"exitCode":1,"timedOut":false,"result":false,"assertions":[false,true]},{"start":1.5,"duration":1.5,"exitCode":null,"signal":"SIGSEGV","timedOut":false,"result":false,"assertions":[true,true]}]}
+`,
+		},
+		{
+			name:        "too few executions in a row met the assertions",
+			strategy:    api.ExecutionStrategyUntil,
+			consecutive: 2,
+			result: api.ExecutionAssertionResults{
+				Duration:           2 * time.Second,
+				Result:             util.NewCompoundResult([]error{nil, exitError(t, 1), nil}),
+				ResultAssertion:    true,
+				ResultVerdict:      api.Verdict{Passed: true, Reason: "the command exited with code 0"},
+				OutputAssertion:    true,
+				OutputVerdicts:     []api.Verdict{{Passed: true}, {Passed: true}},
+				ConsecutiveVerdict: &api.Verdict{Passed: false, Reason: "the longest streak was 1 of 2 executions in a row"},
+				Attempts: []api.Attempt{
+					{Start: 0, Duration: 100 * time.Millisecond, ResultVerdict: api.Verdict{Passed: true}, OutputVerdicts: []api.Verdict{{Passed: true}, {Passed: true}}},
+					{Start: 500 * time.Millisecond, Duration: 100 * time.Millisecond, Result: exitError(t, 1), OutputVerdicts: []api.Verdict{{Passed: true}, {Passed: true}}},
+					{Start: time.Second, Duration: 100 * time.Millisecond, ResultVerdict: api.Verdict{Passed: true}, OutputVerdicts: []api.Verdict{{Passed: true}, {Passed: true}}},
+				},
+			},
+			expectedReport: `{"schemaVersion":"exec-assert/v1","kind":"test","name":"TestName","passed":false,"config":{"command":"echo a && echo b >&2","execute":"until","timeout":60,"interval":0.2,"attemptTimeout":0,"gracePeriod":5,"consecutive":2},"duration":2,"attempts":3,"exitCode":0,"timedOut":false,"result":{"assertion":"success","passed":true,"reason":"the command exited with code 0"},"assertions":[{"kind":"contains","target":"stdout","pattern":"a","match":"literal","passed":true},{"kind":"line-count","target":"stderr","count":{"operator":">=","count":2},"passed":true}],"consecutive":{"required":2,"longest":1,"passed":false,"reason":"the longest streak was 1 of 2 executions in a row"},"stdout":"","stderr":"","combined":[],"timeline":[{"start":0,"duration":0.1,"exitCode":0,"timedOut":false,"result":true,"assertions":[true,true]},{"start":0.5,"duration":0.1,"exitCode":1,"timedOut":false,"result":false,"assertions":[true,true]},{"start":1,"duration":0.1,"exitCode":0,"timedOut":false,"result":true,"assertions":[true,true]}]}
+`,
+		},
+		{
+			name: "timed out",
+			result: api.ExecutionAssertionResults{
+				Duration:        2 * time.Second,
+				Result:          util.NewTimeoutResult(2*time.Second, errors.New("signal: terminated")),
+				ResultAssertion: false,
+				OutputAssertion: true,
+				OutputVerdicts:  []api.Verdict{{Passed: true}, {Passed: true}},
+			},
+			expectedReport: `{"schemaVersion":"exec-assert/v1","kind":"test","name":"TestName","passed":false,"config":{"command":"echo a && echo b >&2","execute":"once","timeout":60,"interval":0.2,"attemptTimeout":0,"gracePeriod":5},"duration":2,"attempts":1,"exitCode":null,"timedOut":true,"result":{"assertion":"success","passed":false,"reason":""},"assertions":[{"kind":"contains","target":"stdout","pattern":"a","match":"literal","passed":true},{"kind":"line-count","target":"stderr","count":{"operator":">=","count":2},"passed":true}],"stdout":"","stderr":"","combined":[]}
+`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		config := config
+		if len(testCase.strategy) > 0 {
+			config.ExecutionStrategy = testCase.strategy
+		}
+		config.Consecutive = testCase.consecutive
+		reporter := JSONReporter{}
+		if declaration := reporter.Declare(config); len(declaration) > 0 {
+			t.Errorf("%s: JSON reporter declared something before the test ran: %q", testCase.name, declaration)
+		}
+		if expected, actual := testCase.expectedReport, reporter.Summarize(testCase.result, false); expected != actual {
+			t.Errorf("%s: JSON reporter did not create correct report for result:\nexpected:\n%s\ngot\n%s", testCase.name, expected, actual)
+		}
+	}
+}
+
+func TestJSONReporterUnencodable(t *testing.T) {
+	reporter := JSONReporter{}
+	reporter.Declare(api.ExecutionAssertionConfig{Name: "TestName", Command: "false", ExecutionStrategy: "backoff", Multiplier: math.NaN()})
+
+	expected := `{"schemaVersion":"exec-assert/v1","kind":"test","name":"TestName","passed":false,"error":"failed to encode report: json: unsupported value: NaN","duration":0,"attempts":0,"exitCode":null,"timedOut":false,"result":{"assertion":"","passed":false,"reason":""},"assertions":null,"stdout":"","stderr":"","combined":null}
+`
+	if actual := reporter.Summarize(api.ExecutionAssertionResults{}, false); expected != actual {
+		t.Errorf("JSON reporter did not report the failure to encode:\nexpected:\n%s\ngot\n%s", expected, actual)
+	}
+}
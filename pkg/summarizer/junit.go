@@ -0,0 +1,244 @@
+package summarizer
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+	"github.com/stevekuznetsov/exec-assert/pkg/util"
+)
+
+// JUnitSuiteName is the name of the test suite that test cases are recorded in
+const JUnitSuiteName = "exec-assert"
+
+// JUnitTestSuites is the root of a JUnit XML report. Attributes and elements written by other tools that are
+// not modeled here are kept as they were, so that adding to their reports does not lose anything.
+type JUnitTestSuites struct {
+	XMLName    xml.Name         `xml:"testsuites"`
+	Tests      int              `xml:"tests,attr"`
+	Failures   int              `xml:"failures,attr"`
+	Time       string           `xml:"time,attr"`
+	Attributes []xml.Attr       `xml:",any,attr"`
+	Suites     []JUnitTestSuite `xml:"testsuite"`
+	Elements   []JUnitElement   `xml:",any"`
+}
+
+// JUnitTestSuite is a suite of test cases in a JUnit XML report
+type JUnitTestSuite struct {
+	Name       string     `xml:"name,attr"`
+	Tests      int        `xml:"tests,attr"`
+	Failures   int        `xml:"failures,attr"`
+	Time       string     `xml:"time,attr"`
+	Attributes []xml.Attr `xml:",any,attr"`
+	// Properties are kept ahead of the test cases, where the JUnit XML schema expects them
+	Properties *JUnitElement   `xml:"properties,omitempty"`
+	TestCases  []JUnitTestCase `xml:"testcase"`
+	Elements   []JUnitElement  `xml:",any"`
+}
+
+// JUnitTestCase is the record of one test in a JUnit XML report
+type JUnitTestCase struct {
+	Name       string         `xml:"name,attr"`
+	ClassName  string         `xml:"classname,attr"`
+	Time       string         `xml:"time,attr"`
+	Attributes []xml.Attr     `xml:",any,attr"`
+	Failure    *JUnitFailure  `xml:"failure,omitempty"`
+	SystemOut  string         `xml:"system-out,omitempty"`
+	SystemErr  string         `xml:"system-err,omitempty"`
+	Elements   []JUnitElement `xml:",any"`
+}
+
+// JUnitFailure records why a test failed
+type JUnitFailure struct {
+	Message    string     `xml:"message,attr"`
+	Attributes []xml.Attr `xml:",any,attr"`
+	Text       string     `xml:",chardata"`
+}
+
+// JUnitElement is an element of a JUnit XML report that is not modeled, like `properties` or `skipped`, kept
+// exactly as it was written
+type JUnitElement struct {
+	XMLName    xml.Name
+	Attributes []xml.Attr `xml:",any,attr"`
+	Content    string     `xml:",innerxml"`
+}
+
+// NewJUnitReporter returns a Reporter that passes the reports of the given Reporter through while
+// recording the test as a JUnit test case, using the text Reporter to describe why the test failed
+func NewJUnitReporter(reporter, text Reporter) *JUnitReporter {
+	return &JUnitReporter{reporter: reporter, text: text}
+}
+
+// JUnitReporter records a test as a JUnit test case as it is reported
+type JUnitReporter struct {
+	// reporter is the Reporter whose reports are passed through
+	reporter Reporter
+
+	// text is the Reporter that describes why the test failed
+	text Reporter
+
+	// config stores the config so it can be recorded with the results
+	config api.ExecutionAssertionConfig
+
+	// testCase is the test case recorded once the test has run
+	testCase JUnitTestCase
+}
+
+var _ Reporter = &JUnitReporter{}
+
+// Declare stores the config of the test and passes the declaration of the wrapped Reporter through
+func (r *JUnitReporter) Declare(config api.ExecutionAssertionConfig) string {
+	r.config = config
+	r.text.Declare(config)
+	return r.reporter.Declare(config)
+}
+
+// Summarize records the test case and passes the summary of the wrapped Reporter through
+func (r *JUnitReporter) Summarize(results api.ExecutionAssertionResults, verbose bool) string {
+	r.testCase = JUnitTestCase{
+		Name:      r.name(),
+		ClassName: JUnitSuiteName,
+		Time:      fmt.Sprintf("%.3f", results.Duration.Seconds()),
+		SystemOut: results.Stdout,
+		SystemErr: results.Stderr,
+	}
+
+	if !(util.Passed(results)) {
+		// the first line of the text summary holds the reasons the test failed, and any explanations follow it
+		summary := r.text.Summarize(results, false)
+		r.testCase.Failure = &JUnitFailure{
+			Message: strings.SplitN(summary, "\n", 2)[0],
+			Text:    describeOutputExplanations(results.OutputVerdicts),
+		}
+	}
+
+	return r.reporter.Summarize(results, verbose)
+}
+
+// Fail records the test case as failed by the error that kept the test from running to completion
+func (r *JUnitReporter) Fail(err error) {
+	r.testCase = JUnitTestCase{
+		Name:      r.name(),
+		ClassName: JUnitSuiteName,
+		Time:      "0.000",
+		Failure:   &JUnitFailure{Message: err.Error()},
+	}
+}
+
+// name is the name of the test case, which is the command for tests that are not named
+func (r *JUnitReporter) name() string {
+	if len(r.config.Name) == 0 {
+		return r.config.Command
+	}
+	return r.config.Name
+}
+
+// TestCase returns the test case recorded once the test has run
+func (r *JUnitReporter) TestCase() JUnitTestCase {
+	return r.testCase
+}
+
+// AppendJUnitTestCase adds the test case to the JUnit XML report at the path, creating the report if
+// it does not exist, so that many invocations can record their tests in the same report. Invocations running
+// at the same time take turns, and the report is replaced whole so that it is never seen half written.
+func AppendJUnitTestCase(path string, testCase JUnitTestCase) error {
+	// the report itself is replaced rather than written to, so a lock on it would not be held by the new report;
+	// the directory holding it is never replaced, and locking it leaves nothing behind
+	lock, err := os.Open(filepath.Dir(path))
+	if err != nil {
+		return fmt.Errorf("failed to open the directory of the JUnit report to lock it: %v", err)
+	}
+	defer lock.Close()
+	if err := syscall.Flock(int(lock.Fd()), syscall.LOCK_EX); err != nil {
+		return fmt.Errorf("failed to lock JUnit report: %v", err)
+	}
+	defer syscall.Flock(int(lock.Fd()), syscall.LOCK_UN)
+
+	var report JUnitTestSuites
+	existing, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read JUnit report: %v", err)
+	}
+	if len(bytes.TrimSpace(existing)) > 0 {
+		if err := xml.Unmarshal(existing, &report); err != nil {
+			return fmt.Errorf("failed to parse JUnit report %s: %v", path, err)
+		}
+	}
+
+	index := -1
+	for i, suite := range report.Suites {
+		if suite.Name == JUnitSuiteName {
+			index = i
+		}
+	}
+	if index < 0 {
+		report.Suites = append(report.Suites, JUnitTestSuite{Name: JUnitSuiteName})
+		index = len(report.Suites) - 1
+	}
+	report.Suites[index].TestCases = append(report.Suites[index].TestCases, testCase)
+	tally(&report)
+
+	data, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to format JUnit report: %v", err)
+	}
+
+	if err := replaceFile(path, append([]byte(xml.Header), append(data, '\n')...)); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %v", err)
+	}
+	return nil
+}
+
+// replaceFile writes the data to a temporary file next to the path and renames it over the path
+func replaceFile(path string, data []byte) error {
+	temporary, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temporary.Name())
+
+	if _, err := temporary.Write(data); err != nil {
+		temporary.Close()
+		return err
+	}
+	if err := temporary.Chmod(0644); err != nil {
+		temporary.Close()
+		return err
+	}
+	if err := temporary.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temporary.Name(), path)
+}
+
+// tally counts the tests, failures and time of every suite and of the report as a whole
+func tally(report *JUnitTestSuites) {
+	report.Tests, report.Failures = 0, 0
+	var totalTime float64
+	for i := range report.Suites {
+		suite := &report.Suites[i]
+		suite.Tests, suite.Failures = len(suite.TestCases), 0
+		var suiteTime float64
+		for _, testCase := range suite.TestCases {
+			if testCase.Failure != nil {
+				suite.Failures++
+			}
+			// a test case with a malformed time written by something else counts for none
+			if seconds, err := strconv.ParseFloat(testCase.Time, 64); err == nil {
+				suiteTime += seconds
+			}
+		}
+		suite.Time = fmt.Sprintf("%.3f", suiteTime)
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		totalTime += suiteTime
+	}
+	report.Time = fmt.Sprintf("%.3f", totalTime)
+}
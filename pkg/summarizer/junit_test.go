@@ -0,0 +1,221 @@
+package summarizer
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+)
+
+func TestJUnitReporter(t *testing.T) {
+	testCases := []struct {
+		name             string
+		config           api.ExecutionAssertionConfig
+		result           api.ExecutionAssertionResults
+		expectedTestCase JUnitTestCase
+	}{
+		{
+			name:   "success is named by the test name",
+			config: api.ExecutionAssertionConfig{Command: "command", ResultAssertion: "success", Name: "TestName"},
+			result: api.ExecutionAssertionResults{
+				Duration:        1500 * time.Millisecond,
+				ResultAssertion: true,
+				Stdout:          "stdout message",
+				Stderr:          "stderr message",
+				OutputAssertion: true,
+			},
+			expectedTestCase: JUnitTestCase{Name: "TestName", ClassName: "exec-assert", Time: "1.500", SystemOut: "stdout message", SystemErr: "stderr message"},
+		},
+		{
+			name:   "failure is named by the command without a test name",
+			config: api.ExecutionAssertionConfig{Command: "command", ResultAssertion: "success"},
+			result: api.ExecutionAssertionResults{
+				Duration:        1 * time.Second,
+				Result:          exitError(t, 3),
+				ResultAssertion: false,
+				Stdout:          "stdout message",
+				OutputAssertion: false,
+				OutputVerdicts:  []api.Verdict{{Reason: "Command output did not contain `text`"}},
+			},
+			expectedTestCase: JUnitTestCase{
+				Name:      "command",
+				ClassName: "exec-assert",
+				Time:      "1.000",
+				Failure: &JUnitFailure{
+					Message: "FAILURE after 1.000s: executing `command` once, expecting success: the execution result assertion failed (exit code 3); the execution output assertion(s) failed",
+					Text:    "Command output did not contain `text`\n",
+				},
+				SystemOut: "stdout message",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		reporter := NewJUnitReporter(&JSONReporter{}, &OnceDeclarerSummarizer{})
+		reporter.Declare(testCase.config)
+		reporter.Summarize(testCase.result, false)
+		expected, actual := testCase.expectedTestCase, reporter.TestCase()
+		if !reflect.DeepEqual(expected.Failure, actual.Failure) {
+			t.Errorf("%s: JUnit reporter did not record correct failure:\nexpected:\n%#v\ngot\n%#v", testCase.name, expected.Failure, actual.Failure)
+		}
+		expected.Failure, actual.Failure = nil, nil
+		if !reflect.DeepEqual(expected, actual) {
+			t.Errorf("%s: JUnit reporter did not record correct test case:\nexpected:\n%#v\ngot\n%#v", testCase.name, expected, actual)
+		}
+	}
+}
+
+func TestAppendJUnitTestCase(t *testing.T) {
+	dir, err := ioutil.TempDir("", "junit")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "junit.xml")
+
+	if err := AppendJUnitTestCase(path, JUnitTestCase{Name: "TestFirst", ClassName: "exec-assert", Time: "1.250", SystemOut: "a < b"}); err != nil {
+		t.Fatalf("failed to create report: %v", err)
+	}
+	if err := AppendJUnitTestCase(path, JUnitTestCase{Name: "TestSecond", ClassName: "exec-assert", Time: "0.500", Failure: &JUnitFailure{Message: "FAILURE", Text: "explanation\n"}}); err != nil {
+		t.Fatalf("failed to add to report: %v", err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read report: %v", err)
+	}
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="2" failures="1" time="1.750">
+  <testsuite name="exec-assert" tests="2" failures="1" time="1.750">
+    <testcase name="TestFirst" classname="exec-assert" time="1.250">
+      <system-out>a &lt; b</system-out>
+    </testcase>
+    <testcase name="TestSecond" classname="exec-assert" time="0.500">
+      <failure message="FAILURE">explanation&#xA;</failure>
+    </testcase>
+  </testsuite>
+</testsuites>
+`
+	if actual := string(data); expected != actual {
+		t.Errorf("did not write correct report:\nexpected:\n%s\ngot\n%s", expected, actual)
+	}
+
+	if err := ioutil.WriteFile(path, []byte("not xml"), 0644); err != nil {
+		t.Fatalf("failed to overwrite report: %v", err)
+	}
+	if err := AppendJUnitTestCase(path, JUnitTestCase{Name: "TestThird"}); err == nil {
+		t.Errorf("expected an error adding to a report that is not XML, got none")
+	}
+}
+
+func TestAppendJUnitTestCaseKeepsUnknownContent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "junit")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "junit.xml")
+
+	existing := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="1" failures="0" time="1.000" name="other">
+  <testsuite name="other" tests="1" failures="0" time="1.000" hostname="runner">
+    <properties><property name="go.version" value="go1.21"/></properties>
+    <testcase name="TestSkipped" classname="other" time="1.000" file="other_test.go">
+      <skipped message="not today"/>
+    </testcase>
+    <system-out>suite output</system-out>
+  </testsuite>
+</testsuites>
+`
+	if err := ioutil.WriteFile(path, []byte(existing), 0644); err != nil {
+		t.Fatalf("failed to write report: %v", err)
+	}
+	if err := AppendJUnitTestCase(path, JUnitTestCase{Name: "TestAdded", ClassName: "exec-assert", Time: "0.500"}); err != nil {
+		t.Fatalf("failed to add to report: %v", err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read report: %v", err)
+	}
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="2" failures="0" time="1.500" name="other">
+  <testsuite name="other" tests="1" failures="0" time="1.000" hostname="runner">
+    <properties><property name="go.version" value="go1.21"/></properties>
+    <testcase name="TestSkipped" classname="other" time="1.000" file="other_test.go">
+      <skipped message="not today"></skipped>
+    </testcase>
+    <system-out>suite output</system-out>
+  </testsuite>
+  <testsuite name="exec-assert" tests="1" failures="0" time="0.500">
+    <testcase name="TestAdded" classname="exec-assert" time="0.500"></testcase>
+  </testsuite>
+</testsuites>
+`
+	if actual := string(data); expected != actual {
+		t.Errorf("did not keep the content of the report:\nexpected:\n%s\ngot\n%s", expected, actual)
+	}
+
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read temporary directory: %v", err)
+	}
+	if expected, actual := 1, len(entries); expected != actual {
+		t.Errorf("expected only the report to be left in the directory, got %d files", actual)
+	}
+}
+
+func TestAppendJUnitTestCaseConcurrently(t *testing.T) {
+	dir, err := ioutil.TempDir("", "junit")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "junit.xml")
+
+	const tests = 20
+	var wait sync.WaitGroup
+	for i := 0; i < tests; i++ {
+		wait.Add(1)
+		go func(i int) {
+			defer wait.Done()
+			if err := AppendJUnitTestCase(path, JUnitTestCase{Name: fmt.Sprintf("Test%d", i), ClassName: "exec-assert", Time: "0.100"}); err != nil {
+				t.Errorf("failed to add test %d to report: %v", i, err)
+			}
+		}(i)
+	}
+	wait.Wait()
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read report: %v", err)
+	}
+	var report JUnitTestSuites
+	if err := xml.Unmarshal(data, &report); err != nil {
+		t.Fatalf("failed to parse report: %v", err)
+	}
+	if expected, actual := tests, report.Tests; expected != actual {
+		t.Errorf("expected %d tests in the report, got %d", expected, actual)
+	}
+}
+
+func TestJUnitReporterFail(t *testing.T) {
+	reporter := NewJUnitReporter(&JSONReporter{}, &OnceDeclarerSummarizer{})
+	reporter.Declare(api.ExecutionAssertionConfig{Command: "pwd", ExecutionStrategy: api.ExecutionStrategyOnce})
+	reporter.Fail(errors.New("command execution failed: no attempts were made"))
+
+	expected := JUnitTestCase{Name: "pwd", ClassName: "exec-assert", Time: "0.000", Failure: &JUnitFailure{Message: "command execution failed: no attempts were made"}}
+	if actual := reporter.TestCase(); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("JUnit reporter did not record correct test case:\nexpected:\n%#v\ngot\n%#v", expected, actual)
+	}
+}
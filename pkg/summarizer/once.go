@@ -6,10 +6,15 @@ import (
 	"strings"
 
 	"github.com/stevekuznetsov/exec-assert/pkg/api"
+	"github.com/stevekuznetsov/exec-assert/pkg/output"
+	"github.com/stevekuznetsov/exec-assert/pkg/util"
 )
 
 // OnceDeclarerSummarizer knows how to interpret test data from a test that runs the command once
 type OnceDeclarerSummarizer struct {
+	// Renderer renders the parts of the summary that stand out and highlights matches in the output
+	Renderer Renderer
+
 	// declaration stores the declaration so it can be used by the summarizer
 	declaration string
 }
@@ -27,7 +32,7 @@ func (s *OnceDeclarerSummarizer) Declare(config api.ExecutionAssertionConfig) st
 
 	declaration.WriteString(fmt.Sprintf("executing %#q once", config.Command))
 
-	assertionDescription := describeAssertions(", expecting", config.ResultAssertion, config.OutputAssertions, config.OutputTests, config.Delimiter)
+	assertionDescription := describeAssertions(", expecting", config.ResultAssertion, config.Assertions)
 	if len(assertionDescription) > 0 {
 		declaration.WriteString(assertionDescription)
 	}
@@ -38,21 +43,13 @@ func (s *OnceDeclarerSummarizer) Declare(config api.ExecutionAssertionConfig) st
 	return s.declaration
 }
 
-func describeAssertions(actionPhrase, resultAssertion, outputAssertion, outputTest, delimiter string) string {
-	var outputAssertions, outputTests []string
-	if len(delimiter) > 0 {
-		outputAssertions = strings.Split(outputAssertion, ",")
-		outputTests = strings.Split(outputTest, delimiter)
-	} else {
-		outputAssertions = []string{outputAssertion}
-		outputTests = []string{outputTest}
-	}
+func describeAssertions(actionPhrase, resultAssertion string, assertions []api.Assertion) string {
 	var description bytes.Buffer
 
 	resultAssertionMeaningful := resultAssertion != "ambivalent"
 	outputAssertionsMeaningful := false
-	for _, assertion := range outputAssertions {
-		if assertion != "ambivalent" {
+	for _, assertion := range assertions {
+		if assertion.Kind != api.OutputAssertionAmbivalent {
 			outputAssertionsMeaningful = true
 			break
 		}
@@ -63,20 +60,16 @@ func describeAssertions(actionPhrase, resultAssertion, outputAssertion, outputTe
 	}
 
 	if resultAssertionMeaningful {
-		description.WriteString(fmt.Sprintf(" %s", resultAssertion))
+		description.WriteString(fmt.Sprintf(" %s", describeResultAssertion(resultAssertion)))
 		if outputAssertionsMeaningful {
 			description.WriteString(" and")
 		}
 	}
 
 	var assertionDescriptions []string
-	for i := 0; i < len(outputAssertions); i++ {
-		assertion, test := outputAssertions[i], outputTests[i]
-		switch assertion {
-		case "contains":
-			assertionDescriptions = append(assertionDescriptions, fmt.Sprintf("contains %#q", test))
-		case "excludes":
-			assertionDescriptions = append(assertionDescriptions, fmt.Sprintf("doesn't contain %#q", test))
+	for _, assertion := range assertions {
+		if description := describeAssertion(assertion); len(description) > 0 {
+			assertionDescriptions = append(assertionDescriptions, description)
 		}
 	}
 
@@ -92,39 +85,203 @@ func describeAssertions(actionPhrase, resultAssertion, outputAssertion, outputTe
 	return description.String()
 }
 
+// describeAssertion describes an output assertion, like "contains `a` on stdout", or nothing for an ambivalent one
+func describeAssertion(assertion api.Assertion) string {
+	target, test := assertion.Target, describeTest(assertion.Pattern, assertion.Options.Match)
+	switch assertion.Kind {
+	case api.OutputAssertionContains:
+		return fmt.Sprintf("contains %s%s", test, describeOutputTarget(target))
+	case api.OutputAssertionExcludes:
+		return fmt.Sprintf("doesn't contain %s%s", test, describeOutputTarget(target))
+	case api.OutputAssertionMatchCount:
+		return fmt.Sprintf("contains %s %s%s", test, output.DescribeCount(assertion.Options.Count, "time"), describeOutputTarget(target))
+	case api.OutputAssertionLineCount:
+		if assertion.Options.Count == (api.CountComparison{Operator: api.CountOperatorExactly, Count: 0}) {
+			return fmt.Sprintf("is empty%s", describeOutputTarget(target))
+		}
+		return fmt.Sprintf("has %s%s", output.DescribeCount(assertion.Options.Count, "line"), describeOutputTarget(target))
+	case api.OutputAssertionInOrder:
+		if target != api.OutputTargetStdout && target != api.OutputTargetStderr {
+			// the order of output across streams is only known for the combined output
+			target = api.OutputTargetCombined
+		}
+		return fmt.Sprintf("contains %s in order%s", describeSequence(assertion.Options.Sequence, assertion.Options.Match), describeOutputTarget(target))
+	case api.OutputAssertionGolden:
+		if target == api.OutputTargetAny || len(target) == 0 {
+			target = api.OutputTargetStdout
+		}
+		return fmt.Sprintf("matches the golden file %#q%s", assertion.Pattern, describeOutputTarget(target))
+	case api.OutputAssertionJSON:
+		return fmt.Sprintf("holds JSON on stdout where %#q", assertion.Pattern)
+	}
+	return ""
+}
+
+// describeTest describes an output test and how it is matched, like "`a+`", "the text `a+`" or "a line matching
+// the glob `a*`"
+func describeTest(test string, matchMode api.MatchMode) string {
+	switch matchMode {
+	case api.MatchModeLiteral:
+		return fmt.Sprintf("the text %#q", test)
+	case api.MatchModeGlob:
+		return fmt.Sprintf("a line matching the glob %#q", test)
+	default:
+		return fmt.Sprintf("%#q", test)
+	}
+}
+
+// describeSequence describes the tests of an in-order assertion, like "`a`, then `b`"
+func describeSequence(tests []string, matchMode api.MatchMode) string {
+	descriptions := make([]string, len(tests))
+	for i, test := range tests {
+		descriptions[i] = describeTest(test, matchMode)
+	}
+	return strings.Join(descriptions, ", then ")
+}
+
+// describeOutputTarget describes the stream an output assertion targets, or nothing if it targets any stream
+func describeOutputTarget(target api.OutputTarget) string {
+	switch target {
+	case api.OutputTargetStdout, api.OutputTargetStderr:
+		return fmt.Sprintf(" on %s", target)
+	case api.OutputTargetCombined:
+		return " on stdout and stderr combined"
+	default:
+		return ""
+	}
+}
+
+// describeResultAssertion describes a result assertion, spelling out the set of codes an exit code assertion expects
+// and the signal a signaled assertion expects
+func describeResultAssertion(resultAssertion string) string {
+	if strings.HasPrefix(resultAssertion, "signaled=") {
+		signal := strings.TrimPrefix(resultAssertion, "signaled=")
+		if signal == "any" {
+			return "termination by a signal"
+		}
+		if parsedSignal, err := util.ParseSignal(signal); err == nil {
+			signal = util.SignalName(parsedSignal)
+		}
+		return fmt.Sprintf("termination by %s", signal)
+	}
+
+	if !strings.HasPrefix(resultAssertion, "exit-code") {
+		return resultAssertion
+	}
+
+	codes := strings.TrimPrefix(resultAssertion, "exit-code")
+	phrase := "exit code"
+	if strings.HasPrefix(codes, "!=") {
+		phrase = "an exit code other than"
+	}
+	codeList := strings.Split(strings.TrimLeft(codes, "!="), ",")
+	if len(codeList) > 1 {
+		return fmt.Sprintf("%s %s or %s", phrase, strings.Join(codeList[:len(codeList)-1], ", "), codeList[len(codeList)-1])
+	}
+	return fmt.Sprintf("%s %s", phrase, codeList[0])
+}
+
+// describeTimeout describes a command execution that was killed for running past its deadline
+func describeTimeout(result error) string {
+	return fmt.Sprintf("the command %s and was killed", result.Error())
+}
+
 // Summarize summarizes test data assuming that the test ran the command once
 func (s *OnceDeclarerSummarizer) Summarize(results api.ExecutionAssertionResults, verbose bool) string {
 	var summary bytes.Buffer
 
-	if results.ResultAssertion && results.OutputAssertion {
-		summary.WriteString(fmt.Sprintf("SUCCESS after %.3fs: %s", results.Duration.Seconds(), s.declaration))
+	if util.Passed(results) {
+		summary.WriteString(fmt.Sprintf("%s after %s: %s", s.Renderer.status(true), s.Renderer.duration(results.Duration), s.declaration))
 	} else {
 		// we do not want the trailing newline on the declaration in this case, as we have more to put on this line
 		declaration := strings.TrimRight(s.declaration, "\n")
-		summary.WriteString(fmt.Sprintf("FAILURE after %.3fs: %s: ", results.Duration.Seconds(), declaration))
-		reasons := []string{}
-		if !results.ResultAssertion {
+		summary.WriteString(fmt.Sprintf("%s after %s: %s: ", s.Renderer.status(false), s.Renderer.duration(results.Duration), declaration))
+		summary.WriteString(fmt.Sprintf("%s\n", strings.Join(describeFailures(results, results.Result), "; ")))
+		summary.WriteString(describeOutputExplanations(results.OutputVerdicts))
+	}
+
+	if !(util.Passed(results)) || verbose {
+		summary.WriteString(describeOutput(results, s.Renderer))
+	}
+
+	return summary.String()
+}
+
+// describeFailures gives the reasons that the assertions on one execution of the command, which had the result, failed
+func describeFailures(results api.ExecutionAssertionResults, result error) []string {
+	reasons := []string{}
+	if util.IsTimeoutResult(result) {
+		reasons = append(reasons, describeTimeout(result))
+	}
+	if !results.ResultAssertion {
+		if code, ok := util.ExitCode(result); ok {
+			reasons = append(reasons, fmt.Sprintf("the execution result assertion failed (exit code %d)", code))
+		} else if signal, ok := util.Signal(result); ok {
+			reasons = append(reasons, fmt.Sprintf("the execution result assertion failed (killed by %s)", util.SignalName(signal)))
+		} else {
 			reasons = append(reasons, "the execution result assertion failed")
 		}
-		if !results.OutputAssertion {
-			reasons = append(reasons, "the execution output assertion(s) failed")
-		}
-		summary.WriteString(fmt.Sprintf("%s\n", strings.Join(reasons, "; ")))
 	}
+	if !results.OutputAssertion {
+		reasons = append(reasons, "the execution output assertion(s) failed")
+	}
+	return reasons
+}
 
-	if !(results.ResultAssertion && results.OutputAssertion) || verbose {
-		if len(results.Stdout) > 0 {
-			summary.WriteString(fmt.Sprintf("Command output to stdout:\n%s\n", results.Stdout))
-		} else {
-			summary.WriteString("Command did not output to stdout.\n")
+// describeOutput formats the output of the last execution of the command, highlighting the matches of output tests
+func describeOutput(results api.ExecutionAssertionResults, renderer Renderer) string {
+	if len(results.Stdout) > 0 && len(results.Stderr) > 0 && len(results.Combined) > 0 {
+		// when the command wrote to both streams, we show the output the way the user would have seen it
+		marks := interleaveMarks(results.Combined, results.Stdout, results.Stderr, results.OutputVerdicts)
+		return fmt.Sprintf("%s\n%s", renderer.header("Command output to stdout and stderr, in the order it was written:"), interleaveLines(results.Combined, marks, renderer))
+	}
+
+	var description bytes.Buffer
+	if len(results.Stdout) > 0 {
+		description.WriteString(fmt.Sprintf("%s\n%s\n", renderer.header("Command output to stdout:"), renderer.highlight(results.Stdout, spansFor(results.OutputVerdicts, api.OutputTargetStdout))))
+	} else {
+		description.WriteString(renderer.header("Command did not output to stdout.") + "\n")
+	}
+
+	if len(results.Stderr) > 0 {
+		description.WriteString(fmt.Sprintf("%s\n%s\n", renderer.header("Command output to stderr:"), renderer.highlight(results.Stderr, spansFor(results.OutputVerdicts, api.OutputTargetStderr))))
+	} else {
+		description.WriteString(renderer.header("Command did not output to stderr.") + "\n")
+	}
+	return description.String()
+}
+
+// describeOutputExplanations formats the reasons that output assertions failed, one after another
+func describeOutputExplanations(verdicts []api.Verdict) string {
+	var description bytes.Buffer
+	for _, verdict := range verdicts {
+		if verdict.Passed || len(verdict.Reason) == 0 {
+			continue
 		}
+		description.WriteString(verdict.Reason)
+		if !strings.HasSuffix(verdict.Reason, "\n") {
+			description.WriteString("\n")
+		}
+	}
+	return description.String()
+}
 
-		if len(results.Stderr) > 0 {
-			summary.WriteString(fmt.Sprintf("Command output to stderr:\n%s\n", results.Stderr))
-		} else {
-			summary.WriteString("Command did not output to stderr.\n")
+// interleaveLines formats lines written to both streams, labelling each line with the stream it was written to
+// and highlighting the marks on each line
+func interleaveLines(lines []api.OutputLine, marks map[int][]mark, renderer Renderer) string {
+	labelled := make([]string, len(lines))
+	labelledMarks := map[int][]mark{}
+	for i, line := range lines {
+		label := fmt.Sprintf("%s: ", line.Stream)
+		labelled[i] = label + line.Text
+		for _, m := range marks[i] {
+			labelledMarks[i] = append(labelledMarks[i], mark{start: m.start + len(label), end: m.end + len(label)})
 		}
 	}
 
-	return summary.String()
+	var interleaved bytes.Buffer
+	for _, line := range renderer.highlightLines(labelled, labelledMarks) {
+		interleaved.WriteString(line + "\n")
+	}
+	return interleaved.String()
 }
@@ -1,10 +1,14 @@
 package summarizer
 
 import (
+	"errors"
+	"fmt"
+	"os/exec"
 	"testing"
 	"time"
 
 	"github.com/stevekuznetsov/exec-assert/pkg/api"
+	"github.com/stevekuznetsov/exec-assert/pkg/util"
 )
 
 func TestOnceDeclare(t *testing.T) {
@@ -19,7 +23,6 @@ func TestOnceDeclare(t *testing.T) {
 				Command:           "command",
 				ExecutionStrategy: "once",
 				ResultAssertion:   "ambivalent",
-				OutputAssertions:  "ambivalent",
 			},
 			expectedDeclaration: "executing `command` once\n",
 		},
@@ -29,7 +32,6 @@ func TestOnceDeclare(t *testing.T) {
 				Command:           "command",
 				ExecutionStrategy: "once",
 				ResultAssertion:   "success",
-				OutputAssertions:  "ambivalent",
 			},
 			expectedDeclaration: "executing `command` once, expecting success\n",
 		},
@@ -39,8 +41,7 @@ func TestOnceDeclare(t *testing.T) {
 				Command:           "command",
 				ExecutionStrategy: "once",
 				ResultAssertion:   "success",
-				OutputAssertions:  "contains",
-				OutputTests:       "text",
+				Assertions:        []api.Assertion{{Kind: api.OutputAssertionContains, Pattern: "text"}},
 			},
 			expectedDeclaration: "executing `command` once, expecting success and output that contains `text`\n",
 		},
@@ -50,8 +51,7 @@ func TestOnceDeclare(t *testing.T) {
 				Command:           "command",
 				ExecutionStrategy: "once",
 				ResultAssertion:   "success",
-				OutputAssertions:  "excludes",
-				OutputTests:       "text",
+				Assertions:        []api.Assertion{{Kind: api.OutputAssertionExcludes, Pattern: "text"}},
 			},
 			expectedDeclaration: "executing `command` once, expecting success and output that doesn't contain `text`\n",
 		},
@@ -61,9 +61,10 @@ func TestOnceDeclare(t *testing.T) {
 				Command:           "command",
 				ExecutionStrategy: "once",
 				ResultAssertion:   "success",
-				OutputAssertions:  "excludes,contains",
-				OutputTests:       "text,othertext",
-				Delimiter:         ",",
+				Assertions: []api.Assertion{
+					{Kind: api.OutputAssertionExcludes, Pattern: "text"},
+					{Kind: api.OutputAssertionContains, Pattern: "othertext"},
+				},
 			},
 			expectedDeclaration: "executing `command` once, expecting success and output that doesn't contain `text`, and contains `othertext`\n",
 		},
@@ -73,7 +74,6 @@ func TestOnceDeclare(t *testing.T) {
 				Command:           "command",
 				ExecutionStrategy: "once",
 				ResultAssertion:   "failure",
-				OutputAssertions:  "ambivalent",
 			},
 			expectedDeclaration: "executing `command` once, expecting failure\n",
 		},
@@ -83,8 +83,7 @@ func TestOnceDeclare(t *testing.T) {
 				Command:           "command",
 				ExecutionStrategy: "once",
 				ResultAssertion:   "failure",
-				OutputAssertions:  "contains",
-				OutputTests:       "text",
+				Assertions:        []api.Assertion{{Kind: api.OutputAssertionContains, Pattern: "text"}},
 			},
 			expectedDeclaration: "executing `command` once, expecting failure and output that contains `text`\n",
 		},
@@ -94,8 +93,7 @@ func TestOnceDeclare(t *testing.T) {
 				Command:           "command",
 				ExecutionStrategy: "once",
 				ResultAssertion:   "failure",
-				OutputAssertions:  "excludes",
-				OutputTests:       "text",
+				Assertions:        []api.Assertion{{Kind: api.OutputAssertionExcludes, Pattern: "text"}},
 			},
 			expectedDeclaration: "executing `command` once, expecting failure and output that doesn't contain `text`\n",
 		},
@@ -105,9 +103,10 @@ func TestOnceDeclare(t *testing.T) {
 				Command:           "command",
 				ExecutionStrategy: "once",
 				ResultAssertion:   "failure",
-				OutputAssertions:  "excludes,contains",
-				OutputTests:       "text,othertext",
-				Delimiter:         ",",
+				Assertions: []api.Assertion{
+					{Kind: api.OutputAssertionExcludes, Pattern: "text"},
+					{Kind: api.OutputAssertionContains, Pattern: "othertext"},
+				},
 			},
 			expectedDeclaration: "executing `command` once, expecting failure and output that doesn't contain `text`, and contains `othertext`\n",
 		},
@@ -117,8 +116,7 @@ func TestOnceDeclare(t *testing.T) {
 				Command:           "command",
 				ExecutionStrategy: "once",
 				ResultAssertion:   "ambivalent",
-				OutputAssertions:  "contains",
-				OutputTests:       "text",
+				Assertions:        []api.Assertion{{Kind: api.OutputAssertionContains, Pattern: "text"}},
 			},
 			expectedDeclaration: "executing `command` once, expecting output that contains `text`\n",
 		},
@@ -128,9 +126,10 @@ func TestOnceDeclare(t *testing.T) {
 				Command:           "command",
 				ExecutionStrategy: "once",
 				ResultAssertion:   "ambivalent",
-				OutputAssertions:  "contains,excludes",
-				OutputTests:       "text,othertext",
-				Delimiter:         ",",
+				Assertions: []api.Assertion{
+					{Kind: api.OutputAssertionContains, Pattern: "text"},
+					{Kind: api.OutputAssertionExcludes, Pattern: "othertext"},
+				},
 			},
 			expectedDeclaration: "executing `command` once, expecting output that contains `text`, and doesn't contain `othertext`\n",
 		},
@@ -140,19 +139,82 @@ func TestOnceDeclare(t *testing.T) {
 				Command:           "command",
 				ExecutionStrategy: "once",
 				ResultAssertion:   "ambivalent",
-				OutputAssertions:  "contains,contains,contains,excludes",
-				OutputTests:       "text,secondtext,thirdtext,othertext",
-				Delimiter:         ",",
+				Assertions: []api.Assertion{
+					{Kind: api.OutputAssertionContains, Pattern: "text"},
+					{Kind: api.OutputAssertionContains, Pattern: "secondtext"},
+					{Kind: api.OutputAssertionContains, Pattern: "thirdtext"},
+					{Kind: api.OutputAssertionExcludes, Pattern: "othertext"},
+				},
 			},
 			expectedDeclaration: "executing `command` once, expecting output that contains `text`, contains `secondtext`, contains `thirdtext`, and doesn't contain `othertext`\n",
 		},
+		{
+			name: "expecting exit code",
+			config: api.ExecutionAssertionConfig{
+				Command:           "command",
+				ExecutionStrategy: "once",
+				ResultAssertion:   "exit-code=3",
+			},
+			expectedDeclaration: "executing `command` once, expecting exit code 3\n",
+		},
+		{
+			name: "expecting one of many exit codes",
+			config: api.ExecutionAssertionConfig{
+				Command:           "command",
+				ExecutionStrategy: "once",
+				ResultAssertion:   "exit-code=1,2,64-78",
+				Assertions:        []api.Assertion{{Kind: api.OutputAssertionContains, Pattern: "text"}},
+			},
+			expectedDeclaration: "executing `command` once, expecting exit code 1, 2 or 64-78 and output that contains `text`\n",
+		},
+		{
+			name: "expecting exit code outside of set",
+			config: api.ExecutionAssertionConfig{
+				Command:           "command",
+				ExecutionStrategy: "once",
+				ResultAssertion:   "exit-code!=0",
+			},
+			expectedDeclaration: "executing `command` once, expecting an exit code other than 0\n",
+		},
+		{
+			name: "expecting signal",
+			config: api.ExecutionAssertionConfig{
+				Command:           "command",
+				ExecutionStrategy: "once",
+				ResultAssertion:   "signaled=segv",
+			},
+			expectedDeclaration: "executing `command` once, expecting termination by SIGSEGV\n",
+		},
+		{
+			name: "expecting any signal",
+			config: api.ExecutionAssertionConfig{
+				Command:           "command",
+				ExecutionStrategy: "once",
+				ResultAssertion:   "signaled=any",
+			},
+			expectedDeclaration: "executing `command` once, expecting termination by a signal\n",
+		},
+		{
+			name: "targeted text assertions",
+			config: api.ExecutionAssertionConfig{
+				Command:           "command",
+				ExecutionStrategy: "once",
+				ResultAssertion:   "failure",
+				Assertions: []api.Assertion{
+					{Kind: api.OutputAssertionContains, Target: api.OutputTargetStderr, Pattern: "text"},
+					{Kind: api.OutputAssertionExcludes, Target: api.OutputTargetStdout, Pattern: "text"},
+					{Kind: api.OutputAssertionContains, Target: api.OutputTargetCombined, Pattern: "both"},
+					{Kind: api.OutputAssertionExcludes, Target: api.OutputTargetAny, Pattern: "othertext"},
+				},
+			},
+			expectedDeclaration: "executing `command` once, expecting failure and output that contains `text` on stderr, doesn't contain `text` on stdout, contains `both` on stdout and stderr combined, and doesn't contain `othertext`\n",
+		},
 		{
 			name: "named expecting success",
 			config: api.ExecutionAssertionConfig{
 				Command:           "command",
 				ExecutionStrategy: "once",
 				ResultAssertion:   "success",
-				OutputAssertions:  "ambivalent",
 				Name:              "test name",
 			},
 			expectedDeclaration: "test name: executing `command` once, expecting success\n",
@@ -172,6 +234,7 @@ func TestOnceSummarize(t *testing.T) {
 		name            string
 		result          api.ExecutionAssertionResults
 		verbose         bool
+		renderer        Renderer
 		expectedSummary string
 	}{
 		{
@@ -233,6 +296,28 @@ Command output to stdout:
 stdout contents
 other contents
 Command did not output to stderr.
+`,
+		},
+		{
+			name: "verbose success with interleaved output to stdout and stderr",
+			result: api.ExecutionAssertionResults{
+				Duration:        1 * time.Second,
+				ResultAssertion: true,
+				Stdout:          "stdout contents\nother contents",
+				Stderr:          "stderr contents",
+				Combined: []api.OutputLine{
+					{Stream: api.OutputStreamStdout, Offset: 1 * time.Millisecond, Text: "stdout contents"},
+					{Stream: api.OutputStreamStderr, Offset: 2 * time.Millisecond, Text: "stderr contents"},
+					{Stream: api.OutputStreamStdout, Offset: 3 * time.Millisecond, Text: "other contents"},
+				},
+				OutputAssertion: true,
+			},
+			verbose: true,
+			expectedSummary: `SUCCESS after 1.000s: declaration
+Command output to stdout and stderr, in the order it was written:
+stdout: stdout contents
+stderr: stderr contents
+stdout: other contents
 `,
 		},
 		{
@@ -244,7 +329,7 @@ Command did not output to stderr.
 				Stderr:          "",
 				OutputAssertion: true,
 			},
-			expectedSummary: `FAILURE after 1.000s: declaration: the execution result assertion failed
+			expectedSummary: `FAILURE after 1.000s: declaration: the execution result assertion failed (exit code 0)
 Command did not output to stdout.
 Command did not output to stderr.
 `,
@@ -272,16 +357,134 @@ Command did not output to stderr.
 				Stderr:          "",
 				OutputAssertion: false,
 			},
-			expectedSummary: `FAILURE after 1.000s: declaration: the execution result assertion failed; the execution output assertion(s) failed
+			expectedSummary: `FAILURE after 1.000s: declaration: the execution result assertion failed (exit code 0); the execution output assertion(s) failed
+Command did not output to stdout.
+Command did not output to stderr.
+`,
+		},
+		{
+			name: "result assertion failure with non-zero exit code",
+			result: api.ExecutionAssertionResults{
+				Duration:        1 * time.Second,
+				Result:          exitError(t, 3),
+				ResultAssertion: false,
+				Stdout:          "",
+				Stderr:          "",
+				OutputAssertion: true,
+			},
+			expectedSummary: `FAILURE after 1.000s: declaration: the execution result assertion failed (exit code 3)
+Command did not output to stdout.
+Command did not output to stderr.
+`,
+		},
+		{
+			name: "result assertion failure with signal death",
+			result: api.ExecutionAssertionResults{
+				Duration:        1 * time.Second,
+				Result:          signalError(t, "SEGV"),
+				ResultAssertion: false,
+				Stdout:          "",
+				Stderr:          "",
+				OutputAssertion: true,
+			},
+			expectedSummary: `FAILURE after 1.000s: declaration: the execution result assertion failed (killed by SIGSEGV)
+Command did not output to stdout.
+Command did not output to stderr.
+`,
+		},
+		{
+			name: "golden file assertion failure",
+			result: api.ExecutionAssertionResults{
+				Duration:        1 * time.Second,
+				ResultAssertion: true,
+				Stdout:          "changed",
+				Stderr:          "",
+				OutputAssertion: false,
+				OutputVerdicts:  []api.Verdict{{Reason: "Command output to stdout did not match golden file out.golden:\n--- out.golden\n+++ stdout\n@@ -1 +1 @@\n-original\n+changed"}},
+			},
+			expectedSummary: `FAILURE after 1.000s: declaration: the execution output assertion(s) failed
+Command output to stdout did not match golden file out.golden:
+--- out.golden
++++ stdout
+@@ -1 +1 @@
+-original
++changed
+Command output to stdout:
+changed
+Command did not output to stderr.
+`,
+		},
+		{
+			name: "timed out execution",
+			result: api.ExecutionAssertionResults{
+				Duration:        2 * time.Second,
+				Result:          util.NewTimeoutResult(2*time.Second, errors.New("signal: terminated")),
+				ResultAssertion: false,
+				Stdout:          "",
+				Stderr:          "",
+				OutputAssertion: true,
+			},
+			expectedSummary: `FAILURE after 2.000s: declaration: the command timed out after 2.000s and was killed; the execution result assertion failed
 Command did not output to stdout.
 Command did not output to stderr.
 `,
 		},
+		{
+			name: "excludes assertion failure highlights the matches",
+			result: api.ExecutionAssertionResults{
+				Duration:        1 * time.Second,
+				ResultAssertion: true,
+				Stdout:          "starting\nerror: one\nready",
+				OutputAssertion: false,
+				OutputVerdicts: []api.Verdict{{
+					Reason: "Command output to stdout contained `error` on line 2",
+					Spans:  []api.Span{{Target: api.OutputTargetStdout, Start: 9, End: 14, Line: 2, Text: "error"}},
+				}},
+			},
+			expectedSummary: `FAILURE after 1.000s: declaration: the execution output assertion(s) failed
+Command output to stdout contained ` + "`error`" + ` on line 2
+Command output to stdout:
+starting
+>>>error<<<: one
+ready
+Command did not output to stderr.
+`,
+		},
+		{
+			name: "matches in interleaved output are highlighted in color with context",
+			result: api.ExecutionAssertionResults{
+				Duration:        1 * time.Second,
+				ResultAssertion: true,
+				Stdout:          "a\nb\nc\nd",
+				Stderr:          "oops",
+				Combined: []api.OutputLine{
+					{Stream: api.OutputStreamStdout, Text: "a"},
+					{Stream: api.OutputStreamStdout, Text: "b"},
+					{Stream: api.OutputStreamStdout, Text: "c"},
+					{Stream: api.OutputStreamStdout, Text: "d"},
+					{Stream: api.OutputStreamStderr, Text: "oops"},
+				},
+				OutputAssertion: true,
+				OutputVerdicts: []api.Verdict{
+					{Passed: true, Spans: []api.Span{{Target: api.OutputTargetStdout, Start: 0, End: 1, Line: 1, Text: "a"}}},
+					{Passed: true, Spans: []api.Span{{Target: api.OutputTargetCombined, Start: 8, End: 10, Line: 5, Text: "oo"}}},
+				},
+			},
+			verbose:  true,
+			renderer: Renderer{Color: true, Context: 1},
+			expectedSummary: "\x1b[1;32mSUCCESS\x1b[0m after \x1b[36m1.000s\x1b[0m: declaration\n" +
+				"\x1b[1mCommand output to stdout and stderr, in the order it was written:\x1b[0m\n" +
+				"stdout: \x1b[1;31ma\x1b[0m\n" +
+				"stdout: b\n" +
+				"...\n" +
+				"stdout: d\n" +
+				"stderr: \x1b[1;31moo\x1b[0mps\n",
+		},
 	}
 
 	for _, testCase := range testCases {
 		// initialize a summarizer with some declaration ending in a newline - we expect this from a properly functioning declarer
-		summarizer := OnceDeclarerSummarizer{declaration: "declaration\n"}
+		summarizer := OnceDeclarerSummarizer{Renderer: testCase.renderer, declaration: "declaration\n"}
 		if expected, actual := testCase.expectedSummary, summarizer.Summarize(testCase.result, testCase.verbose); expected != actual {
 			t.Errorf("%s: once summarizer did not create correct summary for result:\nexpected:\n%q\ngot\n%q", testCase.name, expected, actual)
 		}
@@ -293,55 +496,152 @@ func TestDescribeAssertions(t *testing.T) {
 		name                string
 		actionPhrase        string
 		resultAssertion     string
-		outputAssertions    string
-		outputTests         string
-		delimiter           string
+		assertions          []api.Assertion
 		expectedDescription string
 	}{
 		{
 			name:                "no meaningful assertions",
 			actionPhrase:        "action",
 			resultAssertion:     "ambivalent",
-			outputAssertions:    "ambivalent",
 			expectedDescription: "",
 		},
 		{
 			name:                "no meaningful result assertions",
 			actionPhrase:        "action",
 			resultAssertion:     "ambivalent",
-			outputAssertions:    "contains",
-			outputTests:         "text",
+			assertions:          []api.Assertion{{Kind: api.OutputAssertionContains, Pattern: "text"}},
 			expectedDescription: "action output that contains `text`",
 		},
 		{
 			name:                "no meaningful output assertions",
 			actionPhrase:        "action",
 			resultAssertion:     "success",
-			outputAssertions:    "ambivalent",
 			expectedDescription: "action success",
 		},
 		{
 			name:                "all meaningful assertions",
 			actionPhrase:        "action",
 			resultAssertion:     "failure",
-			outputAssertions:    "contains",
-			outputTests:         "text",
+			assertions:          []api.Assertion{{Kind: api.OutputAssertionContains, Pattern: "text"}},
 			expectedDescription: "action failure and output that contains `text`",
 		},
 		{
-			name:                "many meaningful output assertions",
-			actionPhrase:        "action",
-			resultAssertion:     "ambivalent",
-			outputAssertions:    "ambivalent,contains,excludes,contains",
-			outputTests:         ",text,phrase,verb",
-			delimiter:           ",",
+			name:            "many meaningful output assertions",
+			actionPhrase:    "action",
+			resultAssertion: "ambivalent",
+			assertions: []api.Assertion{
+				{Kind: api.OutputAssertionAmbivalent},
+				{Kind: api.OutputAssertionContains, Pattern: "text"},
+				{Kind: api.OutputAssertionExcludes, Pattern: "phrase"},
+				{Kind: api.OutputAssertionContains, Pattern: "verb"},
+			},
 			expectedDescription: "action output that contains `text`, doesn't contain `phrase`, and contains `verb`",
 		},
+		{
+			name:            "targeted meaningful output assertions",
+			actionPhrase:    "action",
+			resultAssertion: "ambivalent",
+			assertions: []api.Assertion{
+				{Kind: api.OutputAssertionAmbivalent, Target: api.OutputTargetStdout},
+				{Kind: api.OutputAssertionContains, Target: api.OutputTargetStderr, Pattern: "text"},
+			},
+			expectedDescription: "action output that contains `text` on stderr",
+		},
+		{
+			name:            "golden file assertions",
+			actionPhrase:    "action",
+			resultAssertion: "success",
+			assertions: []api.Assertion{
+				{Kind: api.OutputAssertionContains, Pattern: "text"},
+				{Kind: api.OutputAssertionGolden, Target: api.OutputTargetStdout, Pattern: "out.golden"},
+				{Kind: api.OutputAssertionGolden, Target: api.OutputTargetStderr, Pattern: "err.golden"},
+			},
+			expectedDescription: "action success and output that contains `text`, matches the golden file `out.golden` on stdout, and matches the golden file `err.golden` on stderr",
+		},
+		{
+			name:            "JSON assertions",
+			actionPhrase:    "action",
+			resultAssertion: "ambivalent",
+			assertions: []api.Assertion{
+				{Kind: api.OutputAssertionAmbivalent},
+				{Kind: api.OutputAssertionJSON, Pattern: ".items | length >= 3"},
+				{Kind: api.OutputAssertionJSON, Pattern: ".kind == \"List\""},
+			},
+			expectedDescription: "action output that holds JSON on stdout where `.items | length >= 3`, and holds JSON on stdout where `.kind == \"List\"`",
+		},
+		{
+			name:            "literal match mode for every test",
+			actionPhrase:    "action",
+			resultAssertion: "success",
+			assertions: []api.Assertion{
+				{Kind: api.OutputAssertionContains, Pattern: "[ERROR] (x.go:12)", Options: api.AssertionOptions{Match: api.MatchModeLiteral}},
+				{Kind: api.OutputAssertionExcludes, Pattern: "panic", Options: api.AssertionOptions{Match: api.MatchModeLiteral}},
+			},
+			expectedDescription: "action success and output that contains the text `[ERROR] (x.go:12)`, and doesn't contain the text `panic`",
+		},
+		{
+			name:            "match mode for each assertion",
+			actionPhrase:    "action",
+			resultAssertion: "ambivalent",
+			assertions: []api.Assertion{
+				{Kind: api.OutputAssertionContains, Pattern: "a+"},
+				{Kind: api.OutputAssertionInOrder, Target: api.OutputTargetStdout, Options: api.AssertionOptions{Match: api.MatchModeGlob, Sequence: []string{"Listening on *", "ready"}}},
+			},
+			expectedDescription: "action output that contains `a+`, and contains a line matching the glob `Listening on *`, then a line matching the glob `ready` in order on stdout",
+		},
+		{
+			name:                "in-order assertion",
+			actionPhrase:        "action",
+			resultAssertion:     "success",
+			assertions:          []api.Assertion{{Kind: api.OutputAssertionInOrder, Options: api.AssertionOptions{Sequence: []string{"starting", "migrating", "ready"}}}},
+			expectedDescription: "action success and output that contains `starting`, then `migrating`, then `ready` in order on stdout and stderr combined",
+		},
+		{
+			name:            "in-order assertion among other assertions",
+			actionPhrase:    "action",
+			resultAssertion: "ambivalent",
+			assertions: []api.Assertion{
+				{Kind: api.OutputAssertionExcludes, Pattern: "panic"},
+				{Kind: api.OutputAssertionInOrder, Target: api.OutputTargetStdout, Options: api.AssertionOptions{Sequence: []string{"a", "b"}}},
+				{Kind: api.OutputAssertionContains, Target: api.OutputTargetStderr, Pattern: "warning"},
+			},
+			expectedDescription: "action output that doesn't contain `panic`, contains `a`, then `b` in order on stdout, and contains `warning` on stderr",
+		},
+		{
+			name:            "counting assertions",
+			actionPhrase:    "action",
+			resultAssertion: "success",
+			assertions: []api.Assertion{
+				{Kind: api.OutputAssertionMatchCount, Pattern: "ready", Options: api.AssertionOptions{Count: api.CountComparison{Operator: api.CountOperatorExactly, Count: 3}}},
+				{Kind: api.OutputAssertionLineCount, Target: api.OutputTargetStdout, Options: api.AssertionOptions{Count: api.CountComparison{Operator: api.CountOperatorAtLeast, Count: 1}}},
+				{Kind: api.OutputAssertionLineCount, Target: api.OutputTargetStderr, Options: api.AssertionOptions{Count: api.CountComparison{Operator: api.CountOperatorExactly, Count: 0}}},
+				{Kind: api.OutputAssertionMatchCount, Pattern: "error", Options: api.AssertionOptions{Count: api.CountComparison{Operator: api.CountOperatorAtMost, Count: 1}}},
+			},
+			expectedDescription: "action success and output that contains `ready` exactly 3 times, has at least 1 line on stdout, is empty on stderr, and contains `error` at most 1 time",
+		},
 	}
 
 	for _, testCase := range testCases {
-		if expected, actual := testCase.expectedDescription, describeAssertions(testCase.actionPhrase, testCase.resultAssertion, testCase.outputAssertions, testCase.outputTests, testCase.delimiter); expected != actual {
+		if expected, actual := testCase.expectedDescription, describeAssertions(testCase.actionPhrase, testCase.resultAssertion, testCase.assertions); expected != actual {
 			t.Errorf("%s: did not describe assertions correctly:\nexpected:\n%q\ngot:\n%q", testCase.name, expected, actual)
 		}
 	}
 }
+
+// exitError runs a command that exits with the given code in order to generate a real exit error
+func exitError(t *testing.T, code int) error {
+	err := exec.Command("bash", "-c", fmt.Sprintf("exit %d", code)).Run()
+	if _, ok := err.(*exec.ExitError); !ok {
+		t.Fatalf("failed to generate exit error for code %d: %v", code, err)
+	}
+	return err
+}
+
+// signalError runs a command that is killed by the given signal in order to generate a real exit error
+func signalError(t *testing.T, signal string) error {
+	err := exec.Command("bash", "-c", fmt.Sprintf("kill -%s $$", signal)).Run()
+	if _, ok := err.(*exec.ExitError); !ok {
+		t.Fatalf("failed to generate exit error for signal %s: %v", signal, err)
+	}
+	return err
+}
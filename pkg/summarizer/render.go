@@ -0,0 +1,245 @@
+package summarizer
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+	"github.com/stevekuznetsov/exec-assert/pkg/output"
+)
+
+const (
+	// matchStartMarker and matchEndMarker surround the matches of output tests when color is not used
+	matchStartMarker = ">>>"
+	matchEndMarker   = "<<<"
+
+	// omittedLines stands in for lines of output left out when only the context around matches is shown
+	omittedLines = "..."
+)
+
+const (
+	// colorReset ends any color
+	colorReset = "\x1b[0m"
+
+	// colorSuccess and colorFailure color the status of a test
+	colorSuccess = "\x1b[1;32m"
+	colorFailure = "\x1b[1;31m"
+
+	// colorDuration colors durations
+	colorDuration = "\x1b[36m"
+
+	// colorHeader colors the headers that introduce the output of each stream
+	colorHeader = "\x1b[1m"
+
+	// colorCount colors the number of executions that had the same output
+	colorCount = "\x1b[33m"
+
+	// colorMatch colors the matches of output tests
+	colorMatch = "\x1b[1;31m"
+)
+
+// Renderer renders the parts of a text report that should stand out, in ANSI color or as plain text,
+// and determines how much of the output of the command is shown
+type Renderer struct {
+	// Color determines if the parts that stand out are rendered in ANSI color; without it, the report is plain
+	// text and matches of output tests are shown between `>>>` and `<<<`
+	Color bool
+
+	// Context is how many lines to show around each match instead of the whole output, or zero to
+	// show the whole output; output without matches is always shown whole
+	Context int
+}
+
+// color surrounds the text with the color, if color is used
+func (r Renderer) color(color, text string) string {
+	if !r.Color {
+		return text
+	}
+	return color + text + colorReset
+}
+
+// status renders the status of a test
+func (r Renderer) status(passed bool) string {
+	if passed {
+		return r.color(colorSuccess, "SUCCESS")
+	}
+	return r.color(colorFailure, "FAILURE")
+}
+
+// duration renders a duration in seconds
+func (r Renderer) duration(duration time.Duration) string {
+	return r.color(colorDuration, fmt.Sprintf("%.3fs", duration.Seconds()))
+}
+
+// header renders a line that introduces the output of a stream, or says that there was none
+func (r Renderer) header(text string) string {
+	return r.color(colorHeader, text)
+}
+
+// Tally renders the tally of the tests in a suite that passed and failed
+func (r Renderer) Tally(passed, total int, failures []string) string {
+	if len(failures) > 0 {
+		return fmt.Sprintf("%s: %d of %d tests passed; failed: %s\n", r.status(false), passed, total, strings.Join(failures, ", "))
+	}
+	return fmt.Sprintf("%s: %d of %d tests passed\n", r.status(true), passed, total)
+}
+
+// countPattern matches the number of executions that had the same output at the start of a line of compressed records
+var countPattern = regexp.MustCompile(`^[0-9]+x`)
+
+// counts renders the numbers of executions that start the lines of compressed records
+func (r Renderer) counts(records string) string {
+	if !r.Color {
+		return records
+	}
+	lines := strings.Split(records, "\n")
+	for i, line := range lines {
+		lines[i] = countPattern.ReplaceAllStringFunc(line, func(count string) string { return r.color(colorCount, count) })
+	}
+	return strings.Join(lines, "\n")
+}
+
+// mark is the part of a line of output that a match covers
+type mark struct {
+	start, end int
+}
+
+// highlight marks the spans in the text and leaves out lines far from them, if context is set
+func (r Renderer) highlight(text string, spans []api.Span) string {
+	return strings.Join(r.highlightLines(strings.Split(text, "\n"), lineMarks(text, spans)), "\n")
+}
+
+// highlightLines marks the lines with their marks and leaves out lines far from any mark, if context is set
+func (r Renderer) highlightLines(lines []string, marks map[int][]mark) []string {
+	var highlighted []string
+	omitting := false
+	for i, line := range lines {
+		if !r.shown(i, marks) {
+			if !omitting {
+				highlighted = append(highlighted, omittedLines)
+				omitting = true
+			}
+			continue
+		}
+		omitting = false
+		highlighted = append(highlighted, r.markLine(line, marks[i]))
+	}
+	return highlighted
+}
+
+// shown determines if the line at the index is close enough to a mark to be shown
+func (r Renderer) shown(index int, marks map[int][]mark) bool {
+	if r.Context <= 0 || len(marks) == 0 {
+		return true
+	}
+	for i := index - r.Context; i <= index+r.Context; i++ {
+		if _, marked := marks[i]; marked {
+			return true
+		}
+	}
+	return false
+}
+
+// markLine surrounds the marked parts of the line with markers or color, merging marks that overlap
+func (r Renderer) markLine(line string, marks []mark) string {
+	if len(marks) == 0 {
+		return line
+	}
+	start, end := matchStartMarker, matchEndMarker
+	if r.Color {
+		start, end = colorMatch, colorReset
+	}
+
+	sorted := append([]mark{}, marks...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].start < sorted[j].start })
+	merged := []mark{sorted[0]}
+	for _, next := range sorted[1:] {
+		if last := &merged[len(merged)-1]; next.start <= last.end {
+			if next.end > last.end {
+				last.end = next.end
+			}
+			continue
+		}
+		merged = append(merged, next)
+	}
+
+	var marked bytes.Buffer
+	position := 0
+	for _, m := range merged {
+		marked.WriteString(line[position:m.start])
+		marked.WriteString(start + line[m.start:m.end] + end)
+		position = m.end
+	}
+	marked.WriteString(line[position:])
+	return marked.String()
+}
+
+// lineMarks finds the parts of each line of the text that the spans cover, by the index of the line;
+// a span across many lines marks a part of each of them
+func lineMarks(text string, spans []api.Span) map[int][]mark {
+	marks := map[int][]mark{}
+	for _, span := range spans {
+		if span.Start >= span.End || span.End > len(text) {
+			// empty matches have nothing to show, and spans past the end were not found in this text
+			continue
+		}
+		lineStart := strings.LastIndex(text[:span.Start], "\n") + 1
+		for index := strings.Count(text[:span.Start], "\n"); lineStart < span.End; index++ {
+			lineEnd := len(text)
+			if next := strings.Index(text[lineStart:], "\n"); next >= 0 {
+				lineEnd = lineStart + next
+			}
+			start, end := span.Start, span.End
+			if start < lineStart {
+				start = lineStart
+			}
+			if end > lineEnd {
+				end = lineEnd
+			}
+			if start < end {
+				marks[index] = append(marks[index], mark{start: start - lineStart, end: end - lineStart})
+			}
+			lineStart = lineEnd + 1
+		}
+	}
+	return marks
+}
+
+// spansFor collects the spans that the output tests matched in the target
+func spansFor(verdicts []api.Verdict, target api.OutputTarget) []api.Span {
+	var spans []api.Span
+	for _, verdict := range verdicts {
+		for _, span := range verdict.Spans {
+			if span.Target == target {
+				spans = append(spans, span)
+			}
+		}
+	}
+	return spans
+}
+
+// interleaveMarks finds the parts of each line written to both streams that the spans cover, by the
+// index of the line, whether the spans matched one stream or the combined output
+func interleaveMarks(lines []api.OutputLine, stdout, stderr string, verdicts []api.Verdict) map[int][]mark {
+	marks := lineMarks(output.CombinedText(lines), spansFor(verdicts, api.OutputTargetCombined))
+
+	// the lines of each stream are written to the combined output in order, so the nth line of
+	// a stream is the nth line in the combined output that was written to it
+	streamMarks := map[api.OutputStream]map[int][]mark{
+		api.OutputStreamStdout: lineMarks(stdout, spansFor(verdicts, api.OutputTargetStdout)),
+		api.OutputStreamStderr: lineMarks(stderr, spansFor(verdicts, api.OutputTargetStderr)),
+	}
+	streamIndex := map[api.OutputStream]int{}
+	for i, line := range lines {
+		marks[i] = append(marks[i], streamMarks[line.Stream][streamIndex[line.Stream]]...)
+		if len(marks[i]) == 0 {
+			delete(marks, i)
+		}
+		streamIndex[line.Stream]++
+	}
+	return marks
+}
@@ -0,0 +1,137 @@
+package summarizer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+)
+
+func TestRendererHighlight(t *testing.T) {
+	testCases := []struct {
+		name              string
+		renderer          Renderer
+		text              string
+		spans             []api.Span
+		expectedHighlight string
+	}{
+		{
+			name:              "no spans leave the text as-is",
+			renderer:          Renderer{Context: 1},
+			text:              "first\nsecond\nthird",
+			expectedHighlight: "first\nsecond\nthird",
+		},
+		{
+			name:              "spans are marked",
+			text:              "first\nsecond\nthird",
+			spans:             []api.Span{{Start: 6, End: 9}, {Start: 13, End: 18}},
+			expectedHighlight: "first\n>>>sec<<<ond\n>>>third<<<",
+		},
+		{
+			name:              "spans are marked in color",
+			renderer:          Renderer{Color: true},
+			text:              "first",
+			spans:             []api.Span{{Start: 1, End: 3}},
+			expectedHighlight: "f\x1b[1;31mir\x1b[0mst",
+		},
+		{
+			name:              "overlapping spans are marked once",
+			text:              "aaaa",
+			spans:             []api.Span{{Start: 0, End: 2}, {Start: 1, End: 3}},
+			expectedHighlight: ">>>aaa<<<a",
+		},
+		{
+			name:              "span across lines is marked on every line",
+			text:              "one\ntwo\nthree",
+			spans:             []api.Span{{Start: 2, End: 9}},
+			expectedHighlight: "on>>>e<<<\n>>>two<<<\n>>>t<<<hree",
+		},
+		{
+			name:              "empty spans are not marked",
+			text:              "one",
+			spans:             []api.Span{{Start: 1, End: 1}},
+			expectedHighlight: "one",
+		},
+		{
+			name:              "lines far from spans are left out",
+			renderer:          Renderer{Context: 1},
+			text:              "1\n2\n3\n4\n5\n6\n7\n8",
+			spans:             []api.Span{{Start: 8, End: 9}},
+			expectedHighlight: "...\n4\n>>>5<<<\n6\n...",
+		},
+		{
+			name:              "context around spans near each other is shown once",
+			renderer:          Renderer{Context: 1},
+			text:              "1\n2\n3\n4\n5",
+			spans:             []api.Span{{Start: 0, End: 1}, {Start: 4, End: 5}},
+			expectedHighlight: ">>>1<<<\n2\n>>>3<<<\n4\n...",
+		},
+	}
+
+	for _, testCase := range testCases {
+		if expected, actual := testCase.expectedHighlight, testCase.renderer.highlight(testCase.text, testCase.spans); expected != actual {
+			t.Errorf("%s: did not highlight text correctly: expected %q, got %q", testCase.name, expected, actual)
+		}
+	}
+}
+
+func TestRendererColor(t *testing.T) {
+	testCases := []struct {
+		name          string
+		render        func(Renderer) string
+		expectedPlain string
+		expectedColor string
+	}{
+		{
+			name:          "success",
+			render:        func(r Renderer) string { return r.status(true) },
+			expectedPlain: "SUCCESS",
+			expectedColor: "\x1b[1;32mSUCCESS\x1b[0m",
+		},
+		{
+			name:          "failure",
+			render:        func(r Renderer) string { return r.status(false) },
+			expectedPlain: "FAILURE",
+			expectedColor: "\x1b[1;31mFAILURE\x1b[0m",
+		},
+		{
+			name:          "duration",
+			render:        func(r Renderer) string { return r.duration(1500 * time.Millisecond) },
+			expectedPlain: "1.500s",
+			expectedColor: "\x1b[36m1.500s\x1b[0m",
+		},
+		{
+			name:          "header",
+			render:        func(r Renderer) string { return r.header("Command output to stdout:") },
+			expectedPlain: "Command output to stdout:",
+			expectedColor: "\x1b[1mCommand output to stdout:\x1b[0m",
+		},
+		{
+			name:          "counts start lines of compressed records",
+			render:        func(r Renderer) string { return r.counts("10x  a\n    12x\n  --\n2x  b\n") },
+			expectedPlain: "10x  a\n    12x\n  --\n2x  b\n",
+			expectedColor: "\x1b[33m10x\x1b[0m  a\n    12x\n  --\n\x1b[33m2x\x1b[0m  b\n",
+		},
+		{
+			name:          "tally with failures",
+			render:        func(r Renderer) string { return r.Tally(1, 3, []string{"TestA", "TestB"}) },
+			expectedPlain: "FAILURE: 1 of 3 tests passed; failed: TestA, TestB\n",
+			expectedColor: "\x1b[1;31mFAILURE\x1b[0m: 1 of 3 tests passed; failed: TestA, TestB\n",
+		},
+		{
+			name:          "tally without failures",
+			render:        func(r Renderer) string { return r.Tally(3, 3, nil) },
+			expectedPlain: "SUCCESS: 3 of 3 tests passed\n",
+			expectedColor: "\x1b[1;32mSUCCESS\x1b[0m: 3 of 3 tests passed\n",
+		},
+	}
+
+	for _, testCase := range testCases {
+		if expected, actual := testCase.expectedPlain, testCase.render(Renderer{}); expected != actual {
+			t.Errorf("%s: did not render plain text correctly: expected %q, got %q", testCase.name, expected, actual)
+		}
+		if expected, actual := testCase.expectedColor, testCase.render(Renderer{Color: true}); expected != actual {
+			t.Errorf("%s: did not render color correctly: expected %q, got %q", testCase.name, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package summarizer
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+	"github.com/stevekuznetsov/exec-assert/pkg/util"
+)
+
+// NewTAPReporter returns a Reporter that reports on a test with a TAP test line, using the text Reporter
+// to describe the test. A test with a number of zero is reported without one.
+func NewTAPReporter(text Reporter, number int) *TAPReporter {
+	return &TAPReporter{text: text, number: number}
+}
+
+// TAPReporter reports on a test with a test line following the Test Anything Protocol, with a YAML
+// diagnostic block when the test fails or when the report is verbose
+type TAPReporter struct {
+	// text is the Reporter that describes the test
+	text Reporter
+
+	// number is the number of the test in the TAP stream, or zero if the test is not numbered
+	number int
+
+	// declaration stores the declaration of the text Reporter so it can be used as the description
+	declaration string
+}
+
+var _ Reporter = &TAPReporter{}
+
+// Declare stores the declaration of the test; nothing is reported until the test has run
+func (r *TAPReporter) Declare(config api.ExecutionAssertionConfig) string {
+	r.declaration = strings.TrimRight(r.text.Declare(config), "\n")
+	return ""
+}
+
+// Summarize reports the test line for the test, followed by diagnostics if the test failed or the report is verbose
+func (r *TAPReporter) Summarize(results api.ExecutionAssertionResults, verbose bool) string {
+	var summary bytes.Buffer
+
+	passed := util.Passed(results)
+	status := "ok"
+	if !passed {
+		status = "not ok"
+	}
+	if r.number > 0 {
+		status = fmt.Sprintf("%s %d", status, r.number)
+	}
+	summary.WriteString(fmt.Sprintf("%s - %s\n", status, escapeDescription(r.declaration)))
+
+	if passed && !verbose {
+		return summary.String()
+	}
+
+	summary.WriteString("  ---\n")
+	if !passed {
+		// the first line of the text summary follows the declaration with the reasons the test failed
+		firstLine := strings.SplitN(r.text.Summarize(results, false), "\n", 2)[0]
+		message := firstLine
+		if index := strings.Index(firstLine, r.declaration+": "); index >= 0 {
+			message = firstLine[index+len(r.declaration)+2:]
+		}
+		summary.WriteString(fmt.Sprintf("  message: %s\n", yamlString(message, "  ")))
+	}
+	summary.WriteString(fmt.Sprintf("  duration: %.3f\n", results.Duration.Seconds()))
+
+	lastResult := lastResult(results.Result)
+	if code, ok := util.ExitCode(lastResult); ok {
+		summary.WriteString(fmt.Sprintf("  exitCode: %d\n", code))
+	} else if signal, ok := util.Signal(lastResult); ok {
+		summary.WriteString(fmt.Sprintf("  signal: %s\n", util.SignalName(signal)))
+	}
+	if util.IsTimeoutResult(lastResult) {
+		summary.WriteString("  timedOut: true\n")
+	}
+
+	if explanations := strings.TrimRight(describeOutputExplanations(results.OutputVerdicts), "\n"); len(explanations) > 0 {
+		summary.WriteString(fmt.Sprintf("  explanations: %s\n", yamlString(explanations, "  ")))
+	}
+	summary.WriteString(fmt.Sprintf("  stdout: %s\n", yamlString(results.Stdout, "  ")))
+	summary.WriteString(fmt.Sprintf("  stderr: %s\n", yamlString(results.Stderr, "  ")))
+	summary.WriteString("  ...\n")
+
+	return summary.String()
+}
+
+// FormatTAPError formats the test line for a test that could not be run, with the error as its diagnostic message
+func FormatTAPError(number int, name string, err error) string {
+	return fmt.Sprintf("not ok %d - %s\n  ---\n  message: %s\n  ...\n", number, escapeDescription(name), yamlString(err.Error(), "  "))
+}
+
+// escapeDescription escapes a test description, as a `#` in it would otherwise start a directive
+func escapeDescription(description string) string {
+	return strings.Replace(description, "#", `\#`, -1)
+}
+
+// yamlString formats a string as a YAML scalar for a key at the indent, using a literal block for
+// text with many lines when the block can hold it exactly, and a double-quoted string otherwise
+func yamlString(value, indent string) string {
+	blockSafe := strings.Contains(value, "\n") && !strings.HasSuffix(value, "\n") &&
+		!strings.HasPrefix(strings.TrimLeft(value, "\n"), " ") && !strings.ContainsAny(value, "\r\t")
+	if !blockSafe {
+		return strconv.Quote(value)
+	}
+
+	var block bytes.Buffer
+	block.WriteString("|-")
+	for _, line := range strings.Split(value, "\n") {
+		block.WriteString("\n")
+		if len(line) > 0 {
+			block.WriteString(indent + "  " + line)
+		}
+	}
+	return block.String()
+}
@@ -0,0 +1,114 @@
+package summarizer
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+	"github.com/stevekuznetsov/exec-assert/pkg/util"
+)
+
+func TestTAPReporter(t *testing.T) {
+	config := api.ExecutionAssertionConfig{
+		Command:           "echo '#1'",
+		ExecutionStrategy: "once",
+		ResultAssertion:   "success",
+		Name:              "TestName",
+	}
+
+	testCases := []struct {
+		name           string
+		number         int
+		result         api.ExecutionAssertionResults
+		verbose        bool
+		expectedReport string
+	}{
+		{
+			name:   "succinct success",
+			number: 3,
+			result: api.ExecutionAssertionResults{
+				Duration:        1 * time.Second,
+				ResultAssertion: true,
+				Stdout:          "#1",
+				OutputAssertion: true,
+			},
+			expectedReport: "ok 3 - TestName: executing `echo '\\#1'` once, expecting success\n",
+		},
+		{
+			name: "verbose success without a number",
+			result: api.ExecutionAssertionResults{
+				Duration:        1 * time.Second,
+				ResultAssertion: true,
+				Stdout:          "#1",
+				OutputAssertion: true,
+			},
+			verbose: true,
+			expectedReport: `ok - TestName: executing ` + "`echo '\\#1'`" + ` once, expecting success
+  ---
+  duration: 1.000
+  exitCode: 0
+  stdout: "#1"
+  stderr: ""
+  ...
+`,
+		},
+		{
+			name:   "failure with explanations and many lines of output",
+			number: 1,
+			result: api.ExecutionAssertionResults{
+				Duration:        2 * time.Second,
+				Result:          exitError(t, 3),
+				ResultAssertion: false,
+				Stdout:          "first\n\nthird",
+				Stderr:          "  indented\nsecond",
+				OutputAssertion: false,
+				OutputVerdicts:  []api.Verdict{{Reason: "Command output did not contain `a`"}, {Reason: "Command output did not contain `b`"}},
+			},
+			expectedReport: `not ok 1 - TestName: executing ` + "`echo '\\#1'`" + ` once, expecting success
+  ---
+  message: "the execution result assertion failed (exit code 3); the execution output assertion(s) failed"
+  duration: 2.000
+  exitCode: 3
+  explanations: |-
+    Command output did not contain ` + "`a`" + `
+    Command output did not contain ` + "`b`" + `
+  stdout: |-
+    first
+
+    third
+  stderr: "  indented\nsecond"
+  ...
+`,
+		},
+		{
+			name:   "timed out",
+			number: 2,
+			result: api.ExecutionAssertionResults{
+				Duration:        2 * time.Second,
+				Result:          util.NewTimeoutResult(2*time.Second, errors.New("signal: terminated")),
+				ResultAssertion: false,
+				OutputAssertion: true,
+			},
+			expectedReport: `not ok 2 - TestName: executing ` + "`echo '\\#1'`" + ` once, expecting success
+  ---
+  message: "the command timed out after 2.000s and was killed; the execution result assertion failed"
+  duration: 2.000
+  timedOut: true
+  stdout: ""
+  stderr: ""
+  ...
+`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		reporter := NewTAPReporter(&OnceDeclarerSummarizer{}, testCase.number)
+		if declaration := reporter.Declare(config); len(declaration) > 0 {
+			t.Errorf("%s: TAP reporter declared something before the test ran: %q", testCase.name, declaration)
+		}
+		if expected, actual := testCase.expectedReport, reporter.Summarize(testCase.result, testCase.verbose); expected != actual {
+			t.Errorf("%s: TAP reporter did not create correct report for result:\nexpected:\n%s\ngot\n%s", testCase.name, expected, actual)
+		}
+	}
+}
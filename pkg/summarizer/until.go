@@ -12,8 +12,15 @@ import (
 
 // UntilDeclarerSummarizer knows how to interpret test data and config from a test that runs the command once or more
 type UntilDeclarerSummarizer struct {
+	// Renderer renders the parts of the summary that stand out and highlights matches in the output of the last execution
+	Renderer Renderer
+
 	// declaration stores the declaration so it can be used by the summarizer
 	declaration string
+
+	// resultAssertion and assertions store the assertions so that their verdicts on each execution can be described
+	resultAssertion string
+	assertions      []api.Assertion
 }
 
 var _ Declarer = &UntilDeclarerSummarizer{}
@@ -26,49 +33,203 @@ func (s *UntilDeclarerSummarizer) Declare(config api.ExecutionAssertionConfig) s
 		declaration.WriteString(fmt.Sprintf("%s: ", config.Name))
 	}
 
-	declaration.WriteString(fmt.Sprintf("executing %#q every %.3fs for %.3fs", config.Command, config.Interval.Seconds(), config.Timeout.Seconds()))
+	if config.ExecutionStrategy == api.ExecutionStrategyBackoff {
+		declaration.WriteString(fmt.Sprintf("executing %#q, retrying with backoff %s for %gs", config.Command, describeBackoff(config), config.Timeout.Seconds()))
+	} else {
+		declaration.WriteString(fmt.Sprintf("executing %#q every %.3fs for %.3fs", config.Command, config.Interval.Seconds(), config.Timeout.Seconds()))
+	}
 
-	assertionDescription := describeAssertions(", or until", config.ResultAssertion, config.OutputAssertions, config.OutputTests, config.Delimiter)
+	assertionDescription := describeAssertions(", or until", config.ResultAssertion, config.Assertions)
 	if len(assertionDescription) > 0 {
 		declaration.WriteString(assertionDescription)
+		if config.Consecutive > 1 {
+			declaration.WriteString(fmt.Sprintf(" %d times in a row", config.Consecutive))
+		}
 	}
 
 	declaration.WriteString("\n")
 
 	s.declaration = declaration.String()
+	s.resultAssertion, s.assertions = config.ResultAssertion, config.Assertions
 	return s.declaration
 }
 
+// describeBackoff describes how the interval between executions grows, like "0.2s→5s x2" or "1s→60s x1.5 ±10%"
+func describeBackoff(config api.ExecutionAssertionConfig) string {
+	description := fmt.Sprintf("%gs→%gs x%g", config.Interval.Seconds(), config.MaxInterval.Seconds(), config.Multiplier)
+	if config.Jitter > 0 {
+		description += fmt.Sprintf(" ±%g%%", config.Jitter*100)
+	}
+	return description
+}
+
 // Summarize summarizes test data assuming that the test ran the command once or more
 func (s *UntilDeclarerSummarizer) Summarize(results api.ExecutionAssertionResults, verbose bool) string {
 	var summary bytes.Buffer
 
-	if results.ResultAssertion && results.OutputAssertion {
-		summary.WriteString(fmt.Sprintf("SUCCESS after %.3fs: %s", results.Duration.Seconds(), s.declaration))
+	if util.Passed(results) {
+		summary.WriteString(fmt.Sprintf("%s after %s: %s", s.Renderer.status(true), s.Renderer.duration(results.Duration), s.declaration))
 	} else {
 		// we do not want the trailing newline on the declaration in this case, as we have more to put on this line
 		declaration := strings.TrimRight(s.declaration, "\n")
-		summary.WriteString(fmt.Sprintf("FAILURE after %.3fs: %s: the command timed out waiting for assertions to be met\n", results.Duration.Seconds(), declaration))
+		summary.WriteString(fmt.Sprintf("%s after %s: %s: the command timed out waiting for assertions to be met", s.Renderer.status(false), s.Renderer.duration(results.Duration), declaration))
+		lastResult := lastResult(results.Result)
+		if util.IsTimeoutResult(lastResult) {
+			summary.WriteString(fmt.Sprintf("; the last execution of %s", describeTimeout(lastResult)))
+		} else if code, ok := util.ExitCode(lastResult); ok {
+			summary.WriteString(fmt.Sprintf("; the last execution exited with code %d", code))
+		} else if signal, ok := util.Signal(lastResult); ok {
+			summary.WriteString(fmt.Sprintf("; the last execution was killed by %s", util.SignalName(signal)))
+		}
+		if results.ConsecutiveVerdict != nil && !results.ConsecutiveVerdict.Passed {
+			summary.WriteString(fmt.Sprintf("; %s", results.ConsecutiveVerdict.Reason))
+		}
+		summary.WriteString("\n")
+		summary.WriteString(describeOutputExplanations(results.OutputVerdicts))
 	}
 
-	if !(results.ResultAssertion && results.OutputAssertion) || verbose {
-		if len(results.Stdout) > 0 {
-			summary.WriteString(fmt.Sprintf("Command output to stdout:\n%s", compressRecords(strings.Split(results.Stdout, util.RecordSeparator))))
+	if !(util.Passed(results)) || verbose {
+		if len(results.Attempts) > 0 {
+			summary.WriteString(s.Renderer.header("Attempts:") + "\n")
+			summary.WriteString(describeAttempts(results.Attempts, s.resultAssertion, s.assertions, s.Renderer))
+		}
+
+		stdouts, stderrs := attemptOutputs(results)
+		if hasOutput(stdouts) {
+			summary.WriteString(fmt.Sprintf("%s\n%s", s.Renderer.header("Command output to stdout:"), s.compressRecords(stdouts, spansFor(results.OutputVerdicts, api.OutputTargetStdout))))
 		} else {
-			summary.WriteString("Command did not output to stdout.\n")
+			summary.WriteString(s.Renderer.header("Command did not output to stdout.") + "\n")
 		}
 
-		if len(results.Stderr) > 0 {
-			summary.WriteString(fmt.Sprintf("Command output to stderr:\n%s", compressRecords(strings.Split(results.Stderr, util.RecordSeparator))))
+		if hasOutput(stderrs) {
+			summary.WriteString(fmt.Sprintf("%s\n%s", s.Renderer.header("Command output to stderr:"), s.compressRecords(stderrs, spansFor(results.OutputVerdicts, api.OutputTargetStderr))))
 		} else {
-			summary.WriteString("Command did not output to stderr.\n")
+			summary.WriteString(s.Renderer.header("Command did not output to stderr.") + "\n")
 		}
 	}
 
 	return summary.String()
 }
 
-func compressRecords(records []string) string {
+// attemptOutputs collects the output to stdout and stderr of every execution of the command, in order. Executions
+// that did not output anything are kept, so that the records for both streams line up.
+func attemptOutputs(results api.ExecutionAssertionResults) ([]string, []string) {
+	if len(results.Attempts) == 0 {
+		return []string{results.Stdout}, []string{results.Stderr}
+	}
+
+	var stdouts, stderrs []string
+	for _, attempt := range results.Attempts {
+		stdouts = append(stdouts, attempt.Stdout)
+		stderrs = append(stderrs, attempt.Stderr)
+	}
+	return stdouts, stderrs
+}
+
+// hasOutput determines if any execution of the command output anything
+func hasOutput(records []string) bool {
+	for _, record := range records {
+		if len(record) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// describeAttempts describes when each execution started, how it ended and how long it took, and the verdict of
+// every assertion on it, like "attempt 7 at +3.200s: exit 7 in 1.100s, success: no, contains `ready` on stdout: no";
+// a run of executions that ended the same way with the same verdicts is described once, with the range of their
+// start times and durations, like "attempts 3-40 at +1.000s to +38.000s: exit 7 in 0.010s to 0.020s, success: no"
+func describeAttempts(attempts []api.Attempt, resultAssertion string, assertions []api.Assertion, renderer Renderer) string {
+	var description bytes.Buffer
+	for first := 0; first < len(attempts); {
+		outcome := describeAttemptOutcome(attempts[first], resultAssertion, assertions)
+		last := first
+		shortest, longest := attempts[first].Duration, attempts[first].Duration
+		for last+1 < len(attempts) && describeAttemptOutcome(attempts[last+1], resultAssertion, assertions) == outcome {
+			last++
+			if duration := attempts[last].Duration; duration < shortest {
+				shortest = duration
+			} else if duration > longest {
+				longest = duration
+			}
+		}
+
+		durations := renderer.duration(shortest)
+		if longestDuration := renderer.duration(longest); longestDuration != durations {
+			durations = fmt.Sprintf("%s to %s", durations, longestDuration)
+		}
+		if first == last {
+			description.WriteString(fmt.Sprintf("attempt %d at +%s: ", first+1, renderer.duration(attempts[first].Start)))
+		} else {
+			description.WriteString(fmt.Sprintf("attempts %d-%d at +%s to +%s: ", first+1, last+1, renderer.duration(attempts[first].Start), renderer.duration(attempts[last].Start)))
+		}
+		description.WriteString(fmt.Sprintf("%s in %s%s\n", outcome.result, durations, outcome.verdicts))
+		first = last + 1
+	}
+	return description.String()
+}
+
+// attemptOutcome describes how one execution of the command ended and the verdicts of the assertions on it
+type attemptOutcome struct {
+	result   string
+	verdicts string
+}
+
+// describeAttemptOutcome describes how one execution of the command ended, like "exit 7", and the verdict of every
+// assertion on it, like ", success: no, contains `ready` on stdout: no"
+func describeAttemptOutcome(attempt api.Attempt, resultAssertion string, assertions []api.Assertion) attemptOutcome {
+	var verdicts []string
+	if resultAssertion != "ambivalent" {
+		verdicts = append(verdicts, fmt.Sprintf("%s: %s", describeResultAssertion(resultAssertion), describeVerdict(attempt.ResultVerdict)))
+	}
+	for j, assertion := range assertions {
+		if assertionDescription := describeAssertion(assertion); len(assertionDescription) > 0 && j < len(attempt.OutputVerdicts) {
+			verdicts = append(verdicts, fmt.Sprintf("%s: %s", assertionDescription, describeVerdict(attempt.OutputVerdicts[j])))
+		}
+	}
+
+	outcome := attemptOutcome{result: describeAttemptResult(attempt.Result)}
+	for _, verdict := range verdicts {
+		outcome.verdicts += ", " + verdict
+	}
+	return outcome
+}
+
+// describeAttemptResult briefly describes how one execution of the command ended, like "exit 7" or "killed by SIGTERM"
+func describeAttemptResult(result error) string {
+	if util.IsTimeoutResult(result) {
+		return "timed out"
+	}
+	if code, ok := util.ExitCode(result); ok {
+		return fmt.Sprintf("exit %d", code)
+	}
+	if signal, ok := util.Signal(result); ok {
+		return fmt.Sprintf("killed by %s", util.SignalName(signal))
+	}
+	return "failed to run"
+}
+
+// describeVerdict briefly describes whether an assertion was met
+func describeVerdict(verdict api.Verdict) string {
+	if verdict.Passed {
+		return "yes"
+	}
+	return "no"
+}
+
+// lastResult extracts the result of the last execution from a compound result
+func lastResult(result error) error {
+	if !util.IsCompoundResult(result) {
+		return result
+	}
+
+	return result.(*util.CompoundResult).Last()
+}
+
+// compressRecords formats the output of every execution, counting runs of executions with the same output
+// once, and highlights the spans that output tests matched in the output of the last execution
+func (s *UntilDeclarerSummarizer) compressRecords(records []string, spans []api.Span) string {
 	sequentialRecords := []string{records[0]}
 	numOccurances := []int{1}
 
@@ -93,6 +254,10 @@ func compressRecords(records []string) string {
 		record, count := sequentialRecords[i], numOccurances[i]
 
 		lines := strings.Split(record, "\n")
+		if i == len(sequentialRecords)-1 {
+			// output is only tested after the last execution, so only its output holds the spans
+			lines = strings.Split(s.Renderer.highlight(record, spans), "\n")
+		}
 		fmt.Fprintf(tabbedWriter, "%dx\t\t%s\n", count, lines[0])
 		if len(lines) > 1 {
 			for _, line := range lines[1:] {
@@ -106,5 +271,6 @@ func compressRecords(records []string) string {
 	}
 
 	tabbedWriter.Flush()
-	return compressedRecords.String()
+	// the counts are only colored once the columns are aligned, as the tabwriter would count the escape codes
+	return s.Renderer.counts(compressedRecords.String())
 }
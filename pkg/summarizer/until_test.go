@@ -1,7 +1,7 @@
 package summarizer
 
 import (
-	"strings"
+	"errors"
 	"testing"
 	"time"
 
@@ -21,20 +21,57 @@ func TestUntilDeclare(t *testing.T) {
 				Command:           "command",
 				ExecutionStrategy: "until",
 				ResultAssertion:   "success",
-				OutputAssertions:  "ambivalent",
 				Timeout:           60 * time.Second,
 				Interval:          200 * time.Millisecond,
 			},
 			expectedDeclaration: "executing `command` every 0.200s for 60.000s, or until success\n",
 		},
+		{
+			name: "expecting success three times in a row",
+			config: api.ExecutionAssertionConfig{
+				Command:           "command",
+				ExecutionStrategy: "until",
+				ResultAssertion:   "success",
+				Timeout:           60 * time.Second,
+				Interval:          200 * time.Millisecond,
+				Consecutive:       3,
+			},
+			expectedDeclaration: "executing `command` every 0.200s for 60.000s, or until success 3 times in a row\n",
+		},
+		{
+			name: "backing off",
+			config: api.ExecutionAssertionConfig{
+				Command:           "command",
+				ExecutionStrategy: "backoff",
+				ResultAssertion:   "success",
+				Timeout:           60 * time.Second,
+				Interval:          200 * time.Millisecond,
+				Multiplier:        2,
+				MaxInterval:       5 * time.Second,
+			},
+			expectedDeclaration: "executing `command`, retrying with backoff 0.2s→5s x2 for 60s, or until success\n",
+		},
+		{
+			name: "backing off with jitter",
+			config: api.ExecutionAssertionConfig{
+				Command:           "command",
+				ExecutionStrategy: "backoff",
+				ResultAssertion:   "success",
+				Timeout:           5 * time.Minute,
+				Interval:          time.Second,
+				Multiplier:        1.5,
+				MaxInterval:       time.Minute,
+				Jitter:            0.1,
+			},
+			expectedDeclaration: "executing `command`, retrying with backoff 1s→60s x1.5 ±10% for 300s, or until success\n",
+		},
 		{
 			name: "expecting success and text",
 			config: api.ExecutionAssertionConfig{
 				Command:           "command",
 				ExecutionStrategy: "until",
 				ResultAssertion:   "success",
-				OutputAssertions:  "contains",
-				OutputTests:       "text",
+				Assertions:        []api.Assertion{{Kind: api.OutputAssertionContains, Pattern: "text"}},
 				Timeout:           60 * time.Second,
 				Interval:          200 * time.Millisecond,
 			},
@@ -46,8 +83,7 @@ func TestUntilDeclare(t *testing.T) {
 				Command:           "command",
 				ExecutionStrategy: "until",
 				ResultAssertion:   "success",
-				OutputAssertions:  "excludes",
-				OutputTests:       "text",
+				Assertions:        []api.Assertion{{Kind: api.OutputAssertionExcludes, Pattern: "text"}},
 				Timeout:           60 * time.Second,
 				Interval:          200 * time.Millisecond,
 			},
@@ -59,11 +95,12 @@ func TestUntilDeclare(t *testing.T) {
 				Command:           "command",
 				ExecutionStrategy: "until",
 				ResultAssertion:   "success",
-				OutputAssertions:  "excludes,contains",
-				OutputTests:       "text,othertext",
-				Delimiter:         ",",
-				Timeout:           60 * time.Second,
-				Interval:          200 * time.Millisecond,
+				Assertions: []api.Assertion{
+					{Kind: api.OutputAssertionExcludes, Pattern: "text"},
+					{Kind: api.OutputAssertionContains, Pattern: "othertext"},
+				},
+				Timeout:  60 * time.Second,
+				Interval: 200 * time.Millisecond,
 			},
 			expectedDeclaration: "executing `command` every 0.200s for 60.000s, or until success and output that doesn't contain `text`, and contains `othertext`\n",
 		},
@@ -73,7 +110,6 @@ func TestUntilDeclare(t *testing.T) {
 				Command:           "command",
 				ExecutionStrategy: "until",
 				ResultAssertion:   "failure",
-				OutputAssertions:  "ambivalent",
 				Timeout:           60 * time.Second,
 				Interval:          200 * time.Millisecond,
 			},
@@ -85,8 +121,7 @@ func TestUntilDeclare(t *testing.T) {
 				Command:           "command",
 				ExecutionStrategy: "until",
 				ResultAssertion:   "failure",
-				OutputAssertions:  "contains",
-				OutputTests:       "text",
+				Assertions:        []api.Assertion{{Kind: api.OutputAssertionContains, Pattern: "text"}},
 				Timeout:           60 * time.Second,
 				Interval:          200 * time.Millisecond,
 			},
@@ -98,8 +133,7 @@ func TestUntilDeclare(t *testing.T) {
 				Command:           "command",
 				ExecutionStrategy: "until",
 				ResultAssertion:   "failure",
-				OutputAssertions:  "excludes",
-				OutputTests:       "text",
+				Assertions:        []api.Assertion{{Kind: api.OutputAssertionExcludes, Pattern: "text"}},
 				Timeout:           60 * time.Second,
 				Interval:          200 * time.Millisecond,
 			},
@@ -111,11 +145,12 @@ func TestUntilDeclare(t *testing.T) {
 				Command:           "command",
 				ExecutionStrategy: "until",
 				ResultAssertion:   "failure",
-				OutputAssertions:  "excludes,contains",
-				OutputTests:       "text,othertext",
-				Delimiter:         ",",
-				Timeout:           60 * time.Second,
-				Interval:          200 * time.Millisecond,
+				Assertions: []api.Assertion{
+					{Kind: api.OutputAssertionExcludes, Pattern: "text"},
+					{Kind: api.OutputAssertionContains, Pattern: "othertext"},
+				},
+				Timeout:  60 * time.Second,
+				Interval: 200 * time.Millisecond,
 			},
 			expectedDeclaration: "executing `command` every 0.200s for 60.000s, or until failure and output that doesn't contain `text`, and contains `othertext`\n",
 		},
@@ -125,8 +160,7 @@ func TestUntilDeclare(t *testing.T) {
 				Command:           "command",
 				ExecutionStrategy: "until",
 				ResultAssertion:   "ambivalent",
-				OutputAssertions:  "contains",
-				OutputTests:       "text",
+				Assertions:        []api.Assertion{{Kind: api.OutputAssertionContains, Pattern: "text"}},
 				Timeout:           60 * time.Second,
 				Interval:          200 * time.Millisecond,
 			},
@@ -138,11 +172,12 @@ func TestUntilDeclare(t *testing.T) {
 				Command:           "command",
 				ExecutionStrategy: "until",
 				ResultAssertion:   "ambivalent",
-				OutputAssertions:  "contains,excludes",
-				OutputTests:       "text,othertext",
-				Delimiter:         ",",
-				Timeout:           60 * time.Second,
-				Interval:          200 * time.Millisecond,
+				Assertions: []api.Assertion{
+					{Kind: api.OutputAssertionContains, Pattern: "text"},
+					{Kind: api.OutputAssertionExcludes, Pattern: "othertext"},
+				},
+				Timeout:  60 * time.Second,
+				Interval: 200 * time.Millisecond,
 			},
 			expectedDeclaration: "executing `command` every 0.200s for 60.000s, or until output that contains `text`, and doesn't contain `othertext`\n",
 		},
@@ -152,13 +187,27 @@ func TestUntilDeclare(t *testing.T) {
 				Command:           "command",
 				ExecutionStrategy: "until",
 				ResultAssertion:   "ambivalent",
-				OutputAssertions:  "contains,contains,contains,excludes",
-				OutputTests:       "text,secondtext,thirdtext,othertext",
-				Delimiter:         ",",
+				Assertions: []api.Assertion{
+					{Kind: api.OutputAssertionContains, Pattern: "text"},
+					{Kind: api.OutputAssertionContains, Pattern: "secondtext"},
+					{Kind: api.OutputAssertionContains, Pattern: "thirdtext"},
+					{Kind: api.OutputAssertionExcludes, Pattern: "othertext"},
+				},
+				Timeout:  60 * time.Second,
+				Interval: 200 * time.Millisecond,
+			},
+			expectedDeclaration: "executing `command` every 0.200s for 60.000s, or until output that contains `text`, contains `secondtext`, contains `thirdtext`, and doesn't contain `othertext`\n",
+		},
+		{
+			name: "expecting exit code",
+			config: api.ExecutionAssertionConfig{
+				Command:           "command",
+				ExecutionStrategy: "until",
+				ResultAssertion:   "exit-code=3",
 				Timeout:           60 * time.Second,
 				Interval:          200 * time.Millisecond,
 			},
-			expectedDeclaration: "executing `command` every 0.200s for 60.000s, or until output that contains `text`, contains `secondtext`, contains `thirdtext`, and doesn't contain `othertext`\n",
+			expectedDeclaration: "executing `command` every 0.200s for 60.000s, or until exit code 3\n",
 		},
 		{
 			name: "named expecting success",
@@ -166,7 +215,6 @@ func TestUntilDeclare(t *testing.T) {
 				Command:           "command",
 				ExecutionStrategy: "until",
 				ResultAssertion:   "success",
-				OutputAssertions:  "ambivalent",
 				Name:              "test name",
 				Timeout:           60 * time.Second,
 				Interval:          200 * time.Millisecond,
@@ -224,11 +272,17 @@ Command did not output to stderr.
 				Duration:        1 * time.Second,
 				ResultAssertion: true,
 				Stdout:          "",
-				Stderr:          strings.Join([]string{"stderr contents", "stderr contents"}, util.RecordSeparator),
+				Stderr:          "stderr contents",
 				OutputAssertion: true,
+				Attempts: []api.Attempt{
+					{Start: 0, Duration: 100 * time.Millisecond, Stderr: "stderr contents", ResultVerdict: api.Verdict{Passed: true}},
+					{Start: 500 * time.Millisecond, Duration: 100 * time.Millisecond, Stderr: "stderr contents", ResultVerdict: api.Verdict{Passed: true}},
+				},
 			},
 			verbose: true,
 			expectedSummary: `SUCCESS after 1.000s: declaration
+Attempts:
+attempts 1-2 at +0.000s to +0.500s: exit 0 in 0.100s, success: yes
 Command did not output to stdout.
 Command output to stderr:
 2x  stderr contents
@@ -239,12 +293,18 @@ Command output to stderr:
 			result: api.ExecutionAssertionResults{
 				Duration:        1 * time.Second,
 				ResultAssertion: true,
-				Stdout:          strings.Join([]string{"stdout contents", "other contents"}, util.RecordSeparator),
+				Stdout:          "other contents",
 				Stderr:          "",
 				OutputAssertion: true,
+				Attempts: []api.Attempt{
+					{Start: 0, Duration: 100 * time.Millisecond, Stdout: "stdout contents", ResultVerdict: api.Verdict{Passed: true}},
+					{Start: 500 * time.Millisecond, Duration: 100 * time.Millisecond, Stdout: "other contents", ResultVerdict: api.Verdict{Passed: true}},
+				},
 			},
 			verbose: true,
 			expectedSummary: `SUCCESS after 1.000s: declaration
+Attempts:
+attempts 1-2 at +0.000s to +0.500s: exit 0 in 0.100s, success: yes
 Command output to stdout:
 1x  stdout contents
   --
@@ -252,6 +312,24 @@ Command output to stdout:
 Command did not output to stderr.
 `,
 		},
+		{
+			name: "verbose success with output from only some attempts",
+			result: api.ExecutionAssertionResults{
+				Duration:        1 * time.Second,
+				ResultAssertion: true,
+				Stdout:          "",
+				Stderr:          "",
+				OutputAssertion: true,
+				Attempts: []api.Attempt{
+					{Start: 0, Duration: 100 * time.Millisecond, Stdout: "", Stderr: "not yet", ResultVerdict: api.Verdict{Passed: true}},
+					{Start: 500 * time.Millisecond, Duration: 100 * time.Millisecond, Stdout: "ready", ResultVerdict: api.Verdict{Passed: true}},
+				},
+			},
+			verbose: true,
+			// the empty output of an execution is kept so that the output to stdout and stderr line up
+			expectedSummary: "SUCCESS after 1.000s: declaration\nAttempts:\nattempts 1-2 at +0.000s to +0.500s: exit 0 in 0.100s, success: yes\n" +
+				"Command output to stdout:\n1x  \n  --\n1x  ready\nCommand output to stderr:\n1x  not yet\n  --\n1x  \n",
+		},
 		{
 			name: "result assertion failure",
 			result: api.ExecutionAssertionResults{
@@ -261,7 +339,7 @@ Command did not output to stderr.
 				Stderr:          "",
 				OutputAssertion: true,
 			},
-			expectedSummary: `FAILURE after 1.000s: declaration: the command timed out waiting for assertions to be met
+			expectedSummary: `FAILURE after 1.000s: declaration: the command timed out waiting for assertions to be met; the last execution exited with code 0
 Command did not output to stdout.
 Command did not output to stderr.
 `,
@@ -275,7 +353,7 @@ Command did not output to stderr.
 				Stderr:          "",
 				OutputAssertion: false,
 			},
-			expectedSummary: `FAILURE after 1.000s: declaration: the command timed out waiting for assertions to be met
+			expectedSummary: `FAILURE after 1.000s: declaration: the command timed out waiting for assertions to be met; the last execution exited with code 0
 Command did not output to stdout.
 Command did not output to stderr.
 `,
@@ -289,16 +367,115 @@ Command did not output to stderr.
 				Stderr:          "",
 				OutputAssertion: false,
 			},
-			expectedSummary: `FAILURE after 1.000s: declaration: the command timed out waiting for assertions to be met
+			expectedSummary: `FAILURE after 1.000s: declaration: the command timed out waiting for assertions to be met; the last execution exited with code 0
+Command did not output to stdout.
+Command did not output to stderr.
+`,
+		},
+		{
+			name: "last execution exited with non-zero code",
+			result: api.ExecutionAssertionResults{
+				Duration:        3 * time.Second,
+				Result:          util.NewCompoundResult([]error{exitError(t, 1), exitError(t, 2)}),
+				ResultAssertion: false,
+				Stdout:          "",
+				Stderr:          "",
+				OutputAssertion: true,
+			},
+			expectedSummary: `FAILURE after 3.000s: declaration: the command timed out waiting for assertions to be met; the last execution exited with code 2
 Command did not output to stdout.
 Command did not output to stderr.
+`,
+		},
+		{
+			name: "last execution killed by signal",
+			result: api.ExecutionAssertionResults{
+				Duration:        3 * time.Second,
+				Result:          util.NewCompoundResult([]error{exitError(t, 1), signalError(t, "ABRT")}),
+				ResultAssertion: false,
+				Stdout:          "",
+				Stderr:          "",
+				OutputAssertion: true,
+			},
+			expectedSummary: `FAILURE after 3.000s: declaration: the command timed out waiting for assertions to be met; the last execution was killed by SIGABRT
+Command did not output to stdout.
+Command did not output to stderr.
+`,
+		},
+		{
+			name: "last execution timed out",
+			result: api.ExecutionAssertionResults{
+				Duration:        3 * time.Second,
+				Result:          util.NewCompoundResult([]error{errors.New("exit status 1"), util.NewTimeoutResult(1*time.Second, errors.New("signal: terminated"))}),
+				ResultAssertion: false,
+				Stdout:          "",
+				Stderr:          "",
+				OutputAssertion: true,
+			},
+			expectedSummary: `FAILURE after 3.000s: declaration: the command timed out waiting for assertions to be met; the last execution of the command timed out after 1.000s and was killed
+Command did not output to stdout.
+Command did not output to stderr.
+`,
+		},
+		{
+			name: "too few executions in a row met the assertions",
+			result: api.ExecutionAssertionResults{
+				Duration:           3 * time.Second,
+				Result:             util.NewCompoundResult([]error{nil, nil, exitError(t, 1), nil}),
+				ResultAssertion:    true,
+				ResultVerdict:      api.Verdict{Passed: true},
+				OutputAssertion:    true,
+				ConsecutiveVerdict: &api.Verdict{Passed: false, Reason: "the longest streak was 2 of 3 executions in a row"},
+				Attempts: []api.Attempt{
+					{Start: 0, Duration: time.Second, ResultVerdict: api.Verdict{Passed: true}},
+					{Start: time.Second, Duration: 100 * time.Millisecond, ResultVerdict: api.Verdict{Passed: true}},
+					{Start: 1500 * time.Millisecond, Duration: 100 * time.Millisecond, Result: exitError(t, 1)},
+					{Start: 2 * time.Second, Duration: 100 * time.Millisecond, ResultVerdict: api.Verdict{Passed: true}},
+				},
+			},
+			expectedSummary: `FAILURE after 3.000s: declaration: the command timed out waiting for assertions to be met; the last execution exited with code 0; the longest streak was 2 of 3 executions in a row
+Attempts:
+attempts 1-2 at +0.000s to +1.000s: exit 0 in 0.100s to 1.000s, success: yes
+attempt 3 at +1.500s: exit 1 in 0.100s, success: no
+attempt 4 at +2.000s: exit 0 in 0.100s, success: yes
+Command did not output to stdout.
+Command did not output to stderr.
+`,
+		},
+		{
+			name: "matches are highlighted in the output of the last execution",
+			result: api.ExecutionAssertionResults{
+				Duration:        3 * time.Second,
+				Result:          util.NewCompoundResult([]error{nil, nil, nil}),
+				ResultAssertion: true,
+				Stdout:          "error",
+				OutputAssertion: false,
+				Attempts: []api.Attempt{
+					{Start: 0, Duration: time.Second, Stdout: "waiting", ResultVerdict: api.Verdict{Passed: true}},
+					{Start: time.Second, Duration: time.Second, Stdout: "error", ResultVerdict: api.Verdict{Passed: true}},
+					{Start: 2 * time.Second, Duration: time.Second, Stdout: "error", ResultVerdict: api.Verdict{Passed: true}},
+				},
+				OutputVerdicts: []api.Verdict{{
+					Reason: "Command output to stdout contained `err` on line 1",
+					Spans:  []api.Span{{Target: api.OutputTargetStdout, Start: 0, End: 3, Line: 1, Text: "err"}},
+				}},
+			},
+			expectedSummary: `FAILURE after 3.000s: declaration: the command timed out waiting for assertions to be met; the last execution exited with code 0
+Command output to stdout contained ` + "`err`" + ` on line 1
+Attempts:
+attempts 1-3 at +0.000s to +2.000s: exit 0 in 1.000s, success: yes
+Command output to stdout:
+1x  waiting
+  --
+2x  >>>err<<<or
+Command did not output to stderr.
 `,
 		},
 	}
 
 	for _, testCase := range testCases {
 		// initialize a summarizer with some declaration ending in a newline - we expect this from a properly functioning declarer
-		summarizer := UntilDeclarerSummarizer{declaration: "declaration\n"}
+		summarizer := UntilDeclarerSummarizer{declaration: "declaration\n", resultAssertion: "success"}
 		if expected, actual := testCase.expectedSummary, summarizer.Summarize(testCase.result, testCase.verbose); expected != actual {
 			t.Errorf("%s: until summarizer did not create correct summary for config:\nexpected:\n%q\ngot:\n%q", testCase.name, expected, actual)
 		}
@@ -354,8 +531,76 @@ func TestCompressRecords(t *testing.T) {
 	}
 
 	for _, testCase := range testCases {
-		if expected, actual := testCase.expectedCompression, compressRecords(testCase.records); expected != actual {
+		if expected, actual := testCase.expectedCompression, (&UntilDeclarerSummarizer{}).compressRecords(testCase.records, nil); expected != actual {
 			t.Errorf("%s: did not compress records correctly:\nexpected:\n%q\ngot:\n%q", testCase.name, expected, actual)
 		}
 	}
 }
+
+func TestDescribeAttempts(t *testing.T) {
+	assertions := []api.Assertion{
+		{Kind: api.OutputAssertionContains, Target: api.OutputTargetStdout, Pattern: "ready"},
+		{Kind: api.OutputAssertionAmbivalent},
+	}
+	attempts := []api.Attempt{
+		{
+			Start:          0,
+			Duration:       1100 * time.Millisecond,
+			Result:         exitError(t, 7),
+			ResultVerdict:  api.Verdict{Passed: false},
+			OutputVerdicts: []api.Verdict{{Passed: false}, {Passed: true}},
+		},
+		{
+			Start:          1300 * time.Millisecond,
+			Duration:       2 * time.Second,
+			Result:         util.NewTimeoutResult(2*time.Second, errors.New("signal: terminated")),
+			ResultVerdict:  api.Verdict{Passed: false},
+			OutputVerdicts: []api.Verdict{{Passed: true}, {Passed: true}},
+		},
+		{
+			Start:          3500 * time.Millisecond,
+			Duration:       10 * time.Millisecond,
+			Result:         signalError(t, "SIGTERM"),
+			ResultVerdict:  api.Verdict{Passed: false},
+			OutputVerdicts: []api.Verdict{{Passed: true}, {Passed: true}},
+		},
+		{
+			Start:          3700 * time.Millisecond,
+			Duration:       5 * time.Millisecond,
+			ResultVerdict:  api.Verdict{Passed: true},
+			OutputVerdicts: []api.Verdict{{Passed: true}, {Passed: true}},
+		},
+	}
+
+	expected := "attempt 1 at +0.000s: exit 7 in 1.100s, success: no, contains `ready` on stdout: no\n" +
+		"attempt 2 at +1.300s: timed out in 2.000s, success: no, contains `ready` on stdout: yes\n" +
+		"attempt 3 at +3.500s: killed by SIGTERM in 0.010s, success: no, contains `ready` on stdout: yes\n" +
+		"attempt 4 at +3.700s: exit 0 in 0.005s, success: yes, contains `ready` on stdout: yes\n"
+	if actual := describeAttempts(attempts, "success", assertions, Renderer{}); expected != actual {
+		t.Errorf("did not describe attempts correctly:\nexpected:\n%s\ngot:\n%s", expected, actual)
+	}
+}
+
+func TestDescribeAttemptsCollapsesRuns(t *testing.T) {
+	failure := exitError(t, 7)
+	var attempts []api.Attempt
+	for i := 0; i < 40; i++ {
+		attempts = append(attempts, api.Attempt{
+			Start:         time.Duration(i) * time.Second,
+			Duration:      time.Duration(10+i%3) * time.Millisecond,
+			Result:        failure,
+			ResultVerdict: api.Verdict{Passed: false},
+		})
+	}
+	attempts[20].Duration = 2 * time.Second
+	attempts[20].Result = util.NewTimeoutResult(2*time.Second, errors.New("signal: terminated"))
+	attempts = append(attempts, api.Attempt{Start: 40 * time.Second, Duration: 5 * time.Millisecond, ResultVerdict: api.Verdict{Passed: true}})
+
+	expected := "attempts 1-20 at +0.000s to +19.000s: exit 7 in 0.010s to 0.012s, success: no\n" +
+		"attempt 21 at +20.000s: timed out in 2.000s, success: no\n" +
+		"attempts 22-40 at +21.000s to +39.000s: exit 7 in 0.010s to 0.012s, success: no\n" +
+		"attempt 41 at +40.000s: exit 0 in 0.005s, success: yes\n"
+	if actual := describeAttempts(attempts, "success", nil, Renderer{}); expected != actual {
+		t.Errorf("did not describe attempts correctly:\nexpected:\n%s\ngot:\n%s", expected, actual)
+	}
+}
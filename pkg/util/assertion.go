@@ -0,0 +1,62 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+)
+
+// ParseOutputAssertion parses the name of an output assertion as it is written on the command line, where
+// counts follow `contains` or `lines` like `contains>=2` or `lines=3`, and `empty` asserts that there are no lines
+func ParseOutputAssertion(value string) (api.OutputAssertion, api.CountComparison, error) {
+	switch {
+	case value == "contains":
+		return api.OutputAssertionContains, api.CountComparison{}, nil
+	case value == "excludes":
+		return api.OutputAssertionExcludes, api.CountComparison{}, nil
+	case value == "ambivalent":
+		return api.OutputAssertionAmbivalent, api.CountComparison{}, nil
+	case value == "in-order":
+		return api.OutputAssertionInOrder, api.CountComparison{}, nil
+	case value == "empty":
+		return api.OutputAssertionLineCount, api.CountComparison{Operator: api.CountOperatorExactly, Count: 0}, nil
+	case strings.HasPrefix(value, "contains"), strings.HasPrefix(value, "lines"):
+		kind, comparison := api.OutputAssertion(api.OutputAssertionMatchCount), strings.TrimPrefix(value, "contains")
+		if strings.HasPrefix(value, "lines") {
+			kind, comparison = api.OutputAssertionLineCount, strings.TrimPrefix(value, "lines")
+		}
+
+		count, err := parseCountComparison(comparison)
+		if err != nil {
+			return "", api.CountComparison{}, fmt.Errorf("failed to parse counting output assertion %q: %v", value, err)
+		}
+		return kind, count, nil
+	default:
+		return "", api.CountComparison{}, fmt.Errorf("unrecognized output assertion: got %q, expected one of %s", value, []string{"contains", "excludes", "ambivalent", "in-order", "empty", "contains=N", "lines=N"})
+	}
+}
+
+// parseCountComparison parses the comparison given to a counting output assertion, like `=3`, `>=1` or `<=5`
+func parseCountComparison(value string) (api.CountComparison, error) {
+	var comparison api.CountComparison
+	for _, operator := range []api.CountOperator{api.CountOperatorAtLeast, api.CountOperatorAtMost, api.CountOperatorExactly} {
+		if strings.HasPrefix(value, string(operator)) {
+			comparison.Operator = operator
+			value = strings.TrimPrefix(value, string(operator))
+			break
+		}
+	}
+	if len(comparison.Operator) == 0 {
+		return api.CountComparison{}, errors.New("expected the count to follow `=`, `>=` or `<=`")
+	}
+
+	count, err := strconv.Atoi(value)
+	if err != nil || count < 0 {
+		return api.CountComparison{}, fmt.Errorf("count must be a non-negative integer, got %q", value)
+	}
+	comparison.Count = count
+	return comparison, nil
+}
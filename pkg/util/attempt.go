@@ -0,0 +1,37 @@
+package util
+
+import "github.com/stevekuznetsov/exec-assert/pkg/api"
+
+// MetAssertions determines if one execution of a command met every assertion made on it
+func MetAssertions(attempt api.Attempt) bool {
+	if !attempt.ResultVerdict.Passed {
+		return false
+	}
+	for _, verdict := range attempt.OutputVerdicts {
+		if !verdict.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// Passed determines if the results of a test met every assertion made on them
+func Passed(results api.ExecutionAssertionResults) bool {
+	return results.ResultAssertion && results.OutputAssertion && (results.ConsecutiveVerdict == nil || results.ConsecutiveVerdict.Passed)
+}
+
+// Streaks counts the longest run of back-to-back executions that met the assertions, and the run that the last
+// execution ended
+func Streaks(attempts []api.Attempt) (longest, trailing int) {
+	for _, attempt := range attempts {
+		if !MetAssertions(attempt) {
+			trailing = 0
+			continue
+		}
+		trailing++
+		if trailing > longest {
+			longest = trailing
+		}
+	}
+	return longest, trailing
+}
@@ -0,0 +1,95 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stevekuznetsov/exec-assert/pkg/api"
+)
+
+func TestStreaks(t *testing.T) {
+	met := api.Attempt{ResultVerdict: api.Verdict{Passed: true}, OutputVerdicts: []api.Verdict{{Passed: true}}}
+	resultFailed := api.Attempt{OutputVerdicts: []api.Verdict{{Passed: true}}}
+	outputFailed := api.Attempt{ResultVerdict: api.Verdict{Passed: true}, OutputVerdicts: []api.Verdict{{Passed: true}, {Passed: false}}}
+
+	testCases := []struct {
+		name             string
+		attempts         []api.Attempt
+		expectedLongest  int
+		expectedTrailing int
+	}{
+		{
+			name:             "no attempts",
+			attempts:         nil,
+			expectedLongest:  0,
+			expectedTrailing: 0,
+		},
+		{
+			name:             "every attempt met the assertions",
+			attempts:         []api.Attempt{met, met, met},
+			expectedLongest:  3,
+			expectedTrailing: 3,
+		},
+		{
+			name:             "streak broken by a failed result",
+			attempts:         []api.Attempt{met, met, resultFailed, met},
+			expectedLongest:  2,
+			expectedTrailing: 1,
+		},
+		{
+			name:             "streak broken by failed output",
+			attempts:         []api.Attempt{met, outputFailed, met, met, met, outputFailed},
+			expectedLongest:  3,
+			expectedTrailing: 0,
+		},
+	}
+
+	for _, testCase := range testCases {
+		longest, trailing := Streaks(testCase.attempts)
+		if expected, actual := testCase.expectedLongest, longest; expected != actual {
+			t.Errorf("%s: expected the longest streak to be %d, got %d", testCase.name, expected, actual)
+		}
+		if expected, actual := testCase.expectedTrailing, trailing; expected != actual {
+			t.Errorf("%s: expected the trailing streak to be %d, got %d", testCase.name, expected, actual)
+		}
+	}
+}
+
+func TestPassed(t *testing.T) {
+	testCases := []struct {
+		name     string
+		results  api.ExecutionAssertionResults
+		expected bool
+	}{
+		{
+			name:     "every assertion met",
+			results:  api.ExecutionAssertionResults{ResultAssertion: true, OutputAssertion: true},
+			expected: true,
+		},
+		{
+			name:     "result assertion failed",
+			results:  api.ExecutionAssertionResults{ResultAssertion: false, OutputAssertion: true},
+			expected: false,
+		},
+		{
+			name:     "output assertion failed",
+			results:  api.ExecutionAssertionResults{ResultAssertion: true, OutputAssertion: false},
+			expected: false,
+		},
+		{
+			name:     "enough executions in a row met the assertions",
+			results:  api.ExecutionAssertionResults{ResultAssertion: true, OutputAssertion: true, ConsecutiveVerdict: &api.Verdict{Passed: true}},
+			expected: true,
+		},
+		{
+			name:     "too few executions in a row met the assertions",
+			results:  api.ExecutionAssertionResults{ResultAssertion: true, OutputAssertion: true, ConsecutiveVerdict: &api.Verdict{Passed: false}},
+			expected: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		if expected, actual := testCase.expected, Passed(testCase.results); expected != actual {
+			t.Errorf("%s: expected passed to be %v, got %v", testCase.name, expected, actual)
+		}
+	}
+}
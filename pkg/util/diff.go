@@ -0,0 +1,255 @@
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const (
+	// diffContext is the number of unchanged lines shown around each change in a unified diff
+	diffContext = 3
+
+	// maxDiffWork bounds the steps taken to compare two texts, which grow with the number of lines between the first
+	// and last difference times the number of differences; texts that take more to compare are only reported to differ
+	maxDiffWork = 10000000
+)
+
+// diffOperation is one line of an edit script turning one text into another
+type diffOperation struct {
+	// kind is ' ' for a line in both texts, '-' for a line only in the first and '+' for a line only in the second
+	kind byte
+	line string
+}
+
+// UnifiedDiff returns a unified diff between two texts, or nothing if they are the same
+func UnifiedDiff(fromName, toName, from, to string) string {
+	if from == to {
+		return ""
+	}
+
+	var diff bytes.Buffer
+	diff.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", fromName, toName))
+
+	differ := &differ{work: maxDiffWork}
+	operations := differ.editScript(splitLines(from), splitLines(to))
+	if differ.work < 0 {
+		diff.WriteString("the texts differ in too many places to show how\n")
+		return diff.String()
+	}
+
+	// fromLine and toLine track the line numbers, counting from one, of the next operation in each text
+	fromLine, toLine := 1, 1
+	for i := 0; i < len(operations); {
+		if operations[i].kind == ' ' {
+			fromLine, toLine, i = fromLine+1, toLine+1, i+1
+			continue
+		}
+
+		// a hunk starts with the context before this change and runs until there's a gap between
+		// changes that is too long to bridge with context
+		start := i - diffContext
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for end < len(operations) {
+			if operations[end].kind != ' ' {
+				end++
+				continue
+			}
+			gap := end
+			for gap < len(operations) && operations[gap].kind == ' ' {
+				gap++
+			}
+			if gap == len(operations) || gap-end > 2*diffContext {
+				end += diffContext
+				if end > len(operations) {
+					end = len(operations)
+				}
+				break
+			}
+			end = gap
+		}
+
+		hunkFromLine, hunkToLine := fromLine-(i-start), toLine-(i-start)
+		var hunk bytes.Buffer
+		fromCount, toCount := 0, 0
+		for _, operation := range operations[start:end] {
+			hunk.WriteString(fmt.Sprintf("%c%s\n", operation.kind, operation.line))
+			if operation.kind != '+' {
+				fromCount++
+			}
+			if operation.kind != '-' {
+				toCount++
+			}
+		}
+		diff.WriteString(fmt.Sprintf("@@ -%s +%s @@\n", hunkRange(hunkFromLine, fromCount), hunkRange(hunkToLine, toCount)))
+		diff.Write(hunk.Bytes())
+
+		for _, operation := range operations[i:end] {
+			if operation.kind != '+' {
+				fromLine++
+			}
+			if operation.kind != '-' {
+				toLine++
+			}
+		}
+		i = end
+	}
+
+	return diff.String()
+}
+
+// hunkRange formats the range of lines a hunk covers in one text
+func hunkRange(start, count int) string {
+	if count == 0 {
+		// an empty range is given as the line before which it would be
+		return fmt.Sprintf("%d,0", start-1)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// splitLines splits text into lines, treating empty text as having no lines at all
+func splitLines(text string) []string {
+	if len(text) == 0 {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// commonLines counts the lines that two sets of lines start with and, of the lines that are left, the lines that
+// they end with
+func commonLines(from, to []string) (prefix, suffix int) {
+	for prefix < len(from) && prefix < len(to) && from[prefix] == to[prefix] {
+		prefix++
+	}
+	for suffix < len(from)-prefix && suffix < len(to)-prefix && from[len(from)-1-suffix] == to[len(to)-1-suffix] {
+		suffix++
+	}
+	return prefix, suffix
+}
+
+// differ determines how to turn one set of lines into another, giving up once it has done too much work
+type differ struct {
+	// work is how many more steps may be taken to compare the lines
+	work int
+}
+
+// editScript determines the shortest sequence of operations that turns one set of lines into another, using the
+// linear space variant of Myers' algorithm so that large texts with few differences are cheap to compare
+func (d *differ) editScript(from, to []string) []diffOperation {
+	var operations []diffOperation
+
+	// lines at the start and end of both texts are kept as they are, so only the lines between them are compared
+	prefix, suffix := commonLines(from, to)
+	for _, line := range from[:prefix] {
+		operations = append(operations, diffOperation{kind: ' ', line: line})
+	}
+
+	changedFrom, changedTo := from[prefix:len(from)-suffix], to[prefix:len(to)-suffix]
+	if x, y, ok := d.middleSnake(changedFrom, changedTo); ok && (x > 0 || y > 0) && (x < len(changedFrom) || y < len(changedTo)) {
+		operations = append(operations, d.editScript(changedFrom[:x], changedTo[:y])...)
+		operations = append(operations, d.editScript(changedFrom[x:], changedTo[y:])...)
+	} else {
+		// there is nothing in common between the changed lines, or we gave up looking, so they are all removed and
+		// all added
+		for _, line := range changedFrom {
+			operations = append(operations, diffOperation{kind: '-', line: line})
+		}
+		for _, line := range changedTo {
+			operations = append(operations, diffOperation{kind: '+', line: line})
+		}
+	}
+
+	for _, line := range from[len(from)-suffix:] {
+		operations = append(operations, diffOperation{kind: ' ', line: line})
+	}
+	return operations
+}
+
+// middleSnake searches for the shortest edit script from both ends of two sets of lines at once, which must neither
+// start nor end with the same line, and returns where the searches meet so that the sets can be split there; nothing
+// is found if the sets have no lines in common or if the search runs out of work
+func (d *differ) middleSnake(from, to []string) (x, y int, ok bool) {
+	n, m := len(from), len(to)
+	if n == 0 || m == 0 {
+		return 0, 0, false
+	}
+
+	// forward[offset+k] and reverse[offset+k] hold the furthest line in from reached on diagonal k by the searches
+	// from the start and from the end, where the diagonal of a point is its line in from less its line in to
+	maxEdits := (n + m + 1) / 2
+	offset := maxEdits
+	forward, reverse := make([]int, 2*maxEdits+2), make([]int, 2*maxEdits+2)
+	for i := range forward {
+		forward[i], reverse[i] = -1, -1
+	}
+	forward[offset+1], reverse[offset+1] = 0, 0
+
+	// the searches can only meet on diagonals that both reach with the edits each has made, so when the difference in
+	// length is odd, the forward search finds where they meet, and otherwise the reverse search does
+	delta := n - m
+	forwardMeets := delta%2 != 0
+
+	// diagonals that run off the edges of the grid are not searched any further
+	forwardStart, forwardEnd, reverseStart, reverseEnd := 0, 0, 0, 0
+	for edits := 0; edits < maxEdits; edits++ {
+		if d.work -= 2 * (edits + 1); d.work < 0 {
+			return 0, 0, false
+		}
+		for k := -edits + forwardStart; k <= edits-forwardEnd; k += 2 {
+			var x int
+			if k == -edits || (k != edits && forward[offset+k-1] < forward[offset+k+1]) {
+				x = forward[offset+k+1]
+			} else {
+				x = forward[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && from[x] == to[y] {
+				x, y, d.work = x+1, y+1, d.work-1
+			}
+			forward[offset+k] = x
+
+			switch {
+			case x > n:
+				forwardEnd += 2
+			case y > m:
+				forwardStart += 2
+			case forwardMeets:
+				if reverseK := offset + delta - k; reverseK >= 0 && reverseK < len(reverse) && reverse[reverseK] != -1 && x >= n-reverse[reverseK] {
+					return x, y, true
+				}
+			}
+		}
+
+		for k := -edits + reverseStart; k <= edits-reverseEnd; k += 2 {
+			var x int
+			if k == -edits || (k != edits && reverse[offset+k-1] < reverse[offset+k+1]) {
+				x = reverse[offset+k+1]
+			} else {
+				x = reverse[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && from[n-x-1] == to[m-y-1] {
+				x, y, d.work = x+1, y+1, d.work-1
+			}
+			reverse[offset+k] = x
+
+			switch {
+			case x > n:
+				reverseEnd += 2
+			case y > m:
+				reverseStart += 2
+			case !forwardMeets:
+				if forwardK := offset + delta - k; forwardK >= 0 && forwardK < len(forward) && forward[forwardK] != -1 && forward[forwardK] >= n-x {
+					return forward[forwardK], forward[forwardK] - (forwardK - offset), true
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,190 @@
+package util
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	testCases := []struct {
+		name         string
+		from         string
+		to           string
+		expectedDiff string
+	}{
+		{
+			name:         "identical texts",
+			from:         "first\nsecond",
+			to:           "first\nsecond",
+			expectedDiff: "",
+		},
+		{
+			name: "changed line",
+			from: "first\nsecond\nthird",
+			to:   "first\nchanged\nthird",
+			expectedDiff: `--- from
++++ to
+@@ -1,3 +1,3 @@
+ first
+-second
++changed
+ third
+`,
+		},
+		{
+			name: "added lines to empty text",
+			from: "",
+			to:   "first\nsecond",
+			expectedDiff: `--- from
++++ to
+@@ -0,0 +1,2 @@
++first
++second
+`,
+		},
+		{
+			name: "removed line",
+			from: "first\nsecond",
+			to:   "first",
+			expectedDiff: `--- from
++++ to
+@@ -1,2 +1 @@
+ first
+-second
+`,
+		},
+		{
+			name: "distant changes in separate hunks",
+			from: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12",
+			to:   "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ntwelve",
+			expectedDiff: `--- from
++++ to
+@@ -1,4 +1,4 @@
+-1
++one
+ 2
+ 3
+ 4
+@@ -9,4 +9,4 @@
+ 9
+ 10
+ 11
+-12
++twelve
+`,
+		},
+		{
+			name: "nearby changes in one hunk",
+			from: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10",
+			to:   "1\n2\n3\nfour\n5\n6\n7\n8\nnine\n10",
+			expectedDiff: `--- from
++++ to
+@@ -1,10 +1,10 @@
+ 1
+ 2
+ 3
+-4
++four
+ 5
+ 6
+ 7
+ 8
+-9
++nine
+ 10
+`,
+		},
+		{
+			name: "texts that differ in too many places",
+			from: strings.Repeat("a\n", 10000),
+			to:   strings.Repeat("b\n", 10000),
+			expectedDiff: `--- from
++++ to
+the texts differ in too many places to show how
+`,
+		},
+		{
+			name: "long texts with distant changes",
+			from: "first\n" + strings.Repeat("same\n", 100000) + "last",
+			to:   "1st\n" + strings.Repeat("same\n", 100000) + "final",
+			expectedDiff: `--- from
++++ to
+@@ -1,4 +1,4 @@
+-first
++1st
+ same
+ same
+ same
+@@ -99999,4 +99999,4 @@
+ same
+ same
+ same
+-last
++final
+`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		if expected, actual := testCase.expectedDiff, UnifiedDiff("from", "to", testCase.from, testCase.to); expected != actual {
+			t.Errorf("%s: did not create correct unified diff:\nexpected:\n%s\ngot:\n%s", testCase.name, expected, actual)
+		}
+	}
+}
+
+func TestEditScript(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	randomLines := func() []string {
+		lines := make([]string, random.Intn(12))
+		for i := range lines {
+			lines[i] = string(rune('a' + random.Intn(4)))
+		}
+		return lines
+	}
+
+	for i := 0; i < 1000; i++ {
+		from, to := randomLines(), randomLines()
+		operations := (&differ{work: maxDiffWork}).editScript(from, to)
+
+		var edits int
+		var actualFrom, actualTo []string
+		for _, operation := range operations {
+			if operation.kind != '+' {
+				actualFrom = append(actualFrom, operation.line)
+			}
+			if operation.kind != '-' {
+				actualTo = append(actualTo, operation.line)
+			}
+			if operation.kind != ' ' {
+				edits++
+			}
+		}
+		if strings.Join(from, ",") != strings.Join(actualFrom, ",") || strings.Join(to, ",") != strings.Join(actualTo, ",") {
+			t.Errorf("edit script from %q to %q does not turn one into the other: %v", from, to, operations)
+		}
+		if expected := len(from) + len(to) - 2*longestCommonSubsequence(from, to); edits != expected {
+			t.Errorf("edit script from %q to %q is not the shortest: expected %d edits, got %d", from, to, expected, edits)
+		}
+	}
+}
+
+// longestCommonSubsequence measures the longest common subsequence of two sets of lines
+func longestCommonSubsequence(from, to []string) int {
+	common := make([][]int, len(from)+1)
+	for i := range common {
+		common[i] = make([]int, len(to)+1)
+	}
+	for i := len(from) - 1; i >= 0; i-- {
+		for j := len(to) - 1; j >= 0; j-- {
+			if from[i] == to[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else if common[i+1][j] >= common[i][j+1] {
+				common[i][j] = common[i+1][j]
+			} else {
+				common[i][j] = common[i][j+1]
+			}
+		}
+	}
+	return common[0][0]
+}
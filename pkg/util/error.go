@@ -1,19 +1,82 @@
 package util
 
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"time"
+)
+
 // NewCompoundResult wraps a slice of results from command execution in one compound result
 func NewCompoundResult(results []error) error {
 	return &CompoundResult{Results: results}
 }
 
-// CompoundResult wraps a slice of results together
+// CompoundResult records the result of every execution of a command that was executed many times, in order.
+// A nil result is an execution that succeeded.
 type CompoundResult struct {
-	// results are the constituent results of the compound structure
+	// Results are the constituent results of the compound structure
 	Results []error
 }
 
-// Error allows CompoundResult to be an error
+// Error describes how many executions failed and how the last one ended, like "3 of 12 attempts failed; last: exit status 2"
 func (r *CompoundResult) Error() string {
-	return r.Results[len(r.Results)-1].Error()
+	if len(r.Results) == 0 {
+		return "no attempts were made"
+	}
+
+	last := "succeeded"
+	if lastResult := r.Last(); lastResult != nil {
+		last = lastResult.Error()
+	}
+	return fmt.Sprintf("%d of %d attempts failed; last: %s", len(r.Failures()), len(r.Results), last)
+}
+
+// Unwrap exposes every failed execution to errors.Is and errors.As, most recent first, so that errors.As
+// finds the result of the last execution that matches
+func (r *CompoundResult) Unwrap() []error {
+	failures := r.Failures()
+	for i, j := 0, len(failures)-1; i < j; i, j = i+1, j-1 {
+		failures[i], failures[j] = failures[j], failures[i]
+	}
+	return failures
+}
+
+// Last returns the result of the last execution, which is nil if it succeeded or if there were no executions
+func (r *CompoundResult) Last() error {
+	if len(r.Results) == 0 {
+		return nil
+	}
+	return r.Results[len(r.Results)-1]
+}
+
+// Failures returns the results of every execution that failed, in order
+func (r *CompoundResult) Failures() []error {
+	var failures []error
+	for _, result := range r.Results {
+		if result != nil {
+			failures = append(failures, result)
+		}
+	}
+	return failures
+}
+
+// FirstFailure returns the result of the first execution that failed, or nil if none did
+func (r *CompoundResult) FirstFailure() error {
+	failures := r.Failures()
+	if len(failures) == 0 {
+		return nil
+	}
+	return failures[0]
+}
+
+// LastFailure returns the result of the last execution that failed, or nil if none did
+func (r *CompoundResult) LastFailure() error {
+	failures := r.Failures()
+	if len(failures) == 0 {
+		return nil
+	}
+	return failures[len(failures)-1]
 }
 
 // IsCompoundResult determines if a result is a compound result
@@ -25,3 +88,50 @@ func IsCompoundResult(result error) bool {
 	_, ok := result.(*CompoundResult)
 	return ok
 }
+
+// NewTimeoutResult wraps the result of a command execution that was killed for running past its deadline
+func NewTimeoutResult(after time.Duration, result error) error {
+	return &TimeoutResult{After: after, Result: result}
+}
+
+// TimeoutResult is the result of a command execution that was killed for running past its deadline
+type TimeoutResult struct {
+	// After is how long the command had been running when its deadline was reached
+	After time.Duration
+
+	// Result is the result generated by os/exec when the killed command exited
+	Result error
+}
+
+// Error allows TimeoutResult to be an error
+func (r *TimeoutResult) Error() string {
+	return fmt.Sprintf("timed out after %.3fs", r.After.Seconds())
+}
+
+// Unwrap exposes the result of the killed command to errors.Is and errors.As
+func (r *TimeoutResult) Unwrap() error {
+	return r.Result
+}
+
+// IsTimeoutResult determines if a result is or wraps a timeout result
+func IsTimeoutResult(result error) bool {
+	var timeout *TimeoutResult
+	return errors.As(result, &timeout)
+}
+
+// ExitCode determines the code that a command exited with from its result. A command that was killed
+// by a signal or for timing out did not exit with a code, nor did one that failed to run at all.
+func ExitCode(result error) (int, bool) {
+	if result == nil {
+		return 0, true
+	}
+
+	exitErr, ok := result.(*exec.ExitError)
+	if !ok {
+		return 0, false
+	}
+
+	// ExitCode returns -1 for processes that did not exit on their own
+	code := exitErr.ExitCode()
+	return code, code >= 0
+}
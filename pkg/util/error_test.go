@@ -0,0 +1,120 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestCompoundResult(t *testing.T) {
+	timeout := NewTimeoutResult(0, errors.New("signal: terminated"))
+	failure := errors.New("exit status 1")
+
+	testCases := []struct {
+		name                 string
+		results              []error
+		expectedError        string
+		expectedLast         error
+		expectedFirstFailure error
+		expectedLastFailure  error
+	}{
+		{
+			name:          "no results",
+			results:       nil,
+			expectedError: "no attempts were made",
+		},
+		{
+			name:          "only successes",
+			results:       []error{nil, nil},
+			expectedError: "0 of 2 attempts failed; last: succeeded",
+		},
+		{
+			name:                 "success after failures",
+			results:              []error{failure, timeout, nil},
+			expectedError:        "2 of 3 attempts failed; last: succeeded",
+			expectedFirstFailure: failure,
+			expectedLastFailure:  timeout,
+		},
+		{
+			name:                 "failure after successes",
+			results:              []error{nil, nil, timeout},
+			expectedError:        "1 of 3 attempts failed; last: timed out after 0.000s",
+			expectedLast:         timeout,
+			expectedFirstFailure: timeout,
+			expectedLastFailure:  timeout,
+		},
+	}
+
+	for _, testCase := range testCases {
+		result := NewCompoundResult(testCase.results).(*CompoundResult)
+		if expected, actual := testCase.expectedError, result.Error(); expected != actual {
+			t.Errorf("%s: expected error %q, got %q", testCase.name, expected, actual)
+		}
+		if expected, actual := testCase.expectedLast, result.Last(); expected != actual {
+			t.Errorf("%s: expected last result %v, got %v", testCase.name, expected, actual)
+		}
+		if expected, actual := testCase.expectedFirstFailure, result.FirstFailure(); expected != actual {
+			t.Errorf("%s: expected first failure %v, got %v", testCase.name, expected, actual)
+		}
+		if expected, actual := testCase.expectedLastFailure, result.LastFailure(); expected != actual {
+			t.Errorf("%s: expected last failure %v, got %v", testCase.name, expected, actual)
+		}
+	}
+}
+
+func TestCompoundResultUnwrap(t *testing.T) {
+	first, last := exitError(t, 1), exitError(t, 2)
+	timeout := NewTimeoutResult(0, errors.New("signal: terminated"))
+	result := NewCompoundResult([]error{first, nil, timeout, last, nil})
+
+	if expected, actual := "3 of 5 attempts failed; last: succeeded", result.Error(); expected != actual {
+		t.Errorf("expected error %q, got %q", expected, actual)
+	}
+
+	var exitErr *exec.ExitError
+	if !errors.As(result, &exitErr) {
+		t.Fatalf("expected to find an exit error in %v", result)
+	}
+	if expected, actual := 2, exitErr.ExitCode(); expected != actual {
+		t.Errorf("expected to find the exit error of the last failure with code %d, got %d", expected, actual)
+	}
+
+	if !errors.Is(result, timeout) {
+		t.Errorf("expected the timeout to be found in %v", result)
+	}
+	if errors.Is(result, errors.New("exit status 1")) {
+		t.Errorf("did not expect an unrelated error to be found in %v", result)
+	}
+}
+
+func TestCompoundResultUnwrapTimeout(t *testing.T) {
+	killed := exitError(t, 143)
+	timeout := NewTimeoutResult(2*time.Second, killed)
+	result := NewCompoundResult([]error{exitError(t, 1), nil, timeout, nil})
+
+	var exitErr *exec.ExitError
+	if !errors.As(result, &exitErr) {
+		t.Fatalf("expected to find an exit error in %v", result)
+	}
+	if exitErr != killed {
+		t.Errorf("expected to find the exit error of the execution that timed out, got %v", exitErr)
+	}
+
+	if !IsTimeoutResult(fmt.Errorf("wrapped: %w", timeout)) {
+		t.Errorf("expected a wrapped timeout to be a timeout result")
+	}
+	if IsTimeoutResult(killed) {
+		t.Errorf("did not expect the result of the killed command to be a timeout result")
+	}
+}
+
+// exitError runs a command that exits with the given code in order to generate a real exit error
+func exitError(t *testing.T, code int) error {
+	err := exec.Command("bash", "-c", fmt.Sprintf("exit %d", code)).Run()
+	if _, ok := err.(*exec.ExitError); !ok {
+		t.Fatalf("failed to generate exit error for code %d: %v", code, err)
+	}
+	return err
+}
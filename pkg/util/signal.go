@@ -0,0 +1,75 @@
+package util
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// signalNames maps the signals a command is commonly killed by to their names
+var signalNames = map[syscall.Signal]string{
+	syscall.SIGHUP:  "SIGHUP",
+	syscall.SIGINT:  "SIGINT",
+	syscall.SIGQUIT: "SIGQUIT",
+	syscall.SIGILL:  "SIGILL",
+	syscall.SIGTRAP: "SIGTRAP",
+	syscall.SIGABRT: "SIGABRT",
+	syscall.SIGBUS:  "SIGBUS",
+	syscall.SIGFPE:  "SIGFPE",
+	syscall.SIGKILL: "SIGKILL",
+	syscall.SIGUSR1: "SIGUSR1",
+	syscall.SIGSEGV: "SIGSEGV",
+	syscall.SIGUSR2: "SIGUSR2",
+	syscall.SIGPIPE: "SIGPIPE",
+	syscall.SIGALRM: "SIGALRM",
+	syscall.SIGTERM: "SIGTERM",
+	syscall.SIGXCPU: "SIGXCPU",
+	syscall.SIGXFSZ: "SIGXFSZ",
+	syscall.SIGSYS:  "SIGSYS",
+}
+
+// SignalName returns the name of a signal, like `SIGSEGV`
+func SignalName(signal syscall.Signal) string {
+	if name, ok := signalNames[signal]; ok {
+		return name
+	}
+	return fmt.Sprintf("signal %d", int(signal))
+}
+
+// ParseSignal parses a signal from its name, with or without the `SIG` prefix, or from its number
+func ParseSignal(value string) (syscall.Signal, error) {
+	if number, err := strconv.Atoi(value); err == nil {
+		if number <= 0 {
+			return 0, fmt.Errorf("signal number must be positive, got %d", number)
+		}
+		return syscall.Signal(number), nil
+	}
+
+	name := strings.ToUpper(value)
+	if !strings.HasPrefix(name, "SIG") {
+		name = "SIG" + name
+	}
+	for signal, signalName := range signalNames {
+		if signalName == name {
+			return signal, nil
+		}
+	}
+	return 0, fmt.Errorf("unrecognized signal %q", value)
+}
+
+// Signal determines the signal that killed a command from its result. A command that exited on its own,
+// or failed to run at all, was not killed by a signal.
+func Signal(result error) (syscall.Signal, bool) {
+	exitErr, ok := result.(*exec.ExitError)
+	if !ok {
+		return 0, false
+	}
+
+	status, ok := exitErr.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() {
+		return 0, false
+	}
+	return status.Signal(), true
+}
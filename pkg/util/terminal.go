@@ -0,0 +1,20 @@
+package util
+
+import (
+	"io"
+	"os"
+)
+
+// IsTerminal determines if the writer writes to a terminal
+func IsTerminal(writer io.Writer) bool {
+	file, ok := writer.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -1,7 +0,0 @@
-package util
-
-const (
-	// RecordSeparator is the character used to separate records from each other in the content of stdout and stderr output
-	// from the until executor
-	RecordSeparator = "\x1e"
-)
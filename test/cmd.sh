@@ -33,8 +33,22 @@ echo "[INFO] Beginning tests..."
 ./exec-assert --result failure --output contains --test 'for more information' 'grep'
 ./exec-assert --result failure --output excludes --test 'bogus text' 'grep'
 ./exec-assert --result failure --output 'contains,excludes' --test 'for more information#bogus text' --delimiter '#' 'grep'
+./exec-assert --result failure --output 'stderr:contains,stdout:excludes' --test 'for more information#for more information' --delimiter '#' 'grep'
+./exec-assert --output 'stdout:contains,stderr:excludes' --test '/#/' --delimiter '#' 'pwd'
+./exec-assert --output 'combined:contains' --test 'first
+second' 'echo first; sleep 0.1; echo second >&2'
 ./exec-assert --result ambivalent 'exit 0'
 ./exec-assert --result ambivalent 'exit 1'
+./exec-assert --result exit-code=0 'exit 0'
+./exec-assert --result exit-code=3 'exit 3'
+./exec-assert --result exit-code=1,2 'grep pattern /bogus/file'
+./exec-assert --result exit-code=64-78 'exit 70'
+./exec-assert --result 'exit-code!=0' 'exit 1'
+./exec-assert --result signaled=SIGSEGV 'kill -SEGV $$'
+./exec-assert --result signaled=abrt 'kill -ABRT $$'
+./exec-assert --result signaled=any 'kill -KILL $$'
+./exec-assert --result signaled 'kill -TERM $$'
+./exec-assert --match literal --output contains --test 'expecting termination by a signal' "./exec-assert --result signaled 'kill -TERM \$\$'"
 
 # Simple tests of failing invocations
 if ./exec-assert --result failure 'date'; then
@@ -46,6 +60,79 @@ fi
 if ./exec-assert --output contains --test '1999' 'date'; then
 	exit 1
 fi
+if ./exec-assert --result failure --output 'stdout:contains' --test 'for more information' 'grep'; then
+	exit 1
+fi
+if ./exec-assert --result exit-code=1 'exit 2'; then
+	exit 1
+fi
+if ./exec-assert --result 'exit-code!=0' 'exit 0'; then
+	exit 1
+fi
+if ./exec-assert --result signaled=any 'exit 139'; then
+	exit 1
+fi
+if ./exec-assert --result 'exit-code!=0' 'kill -SEGV $$'; then
+	exit 1
+fi
+
+# Counting tests
+./exec-assert --output 'contains=3' --test 'ready' 'echo ready; echo ready >&2; echo ready'
+./exec-assert --output 'stdout:contains>=2,stderr:contains<=0' --test 'a#a' --delimiter '#' 'echo a; echo a'
+./exec-assert --output 'stdout:lines=2' 'echo a; echo b'
+./exec-assert --output 'stderr:empty' 'echo a'
+./exec-assert --output 'stdout:lines=3' "printf 'a\\n\\n\\n'"
+./exec-assert --result failure --output contains --test 'had 2 lines, expected exactly 0 lines' "./exec-assert --output 'stderr:empty' 'printf \"\\n\\n\" >&2'"
+./exec-assert --result failure --output contains --test 'had 1 line, expected exactly 0 lines' "./exec-assert --output 'stderr:empty' 'echo oops >&2'"
+./exec-assert --result failure --output contains --test 'matched `ready` 1 time, expected at least 2 times' "./exec-assert --output 'contains>=2' --test 'ready' 'echo ready'"
+if ./exec-assert --output 'lines=x' 'echo a'; then
+	exit 1
+fi
+
+# Match mode tests
+./exec-assert --match literal --output contains --test '[ERROR] (x.go:12)' 'echo "[ERROR] (x.go:12) something broke"'
+./exec-assert --match literal --output excludes --test 'a+' 'echo aaa'
+./exec-assert --match glob --output contains --test 'Listening on *:80?0' 'echo starting; echo Listening on 0.0.0.0:8080'
+./exec-assert --match glob --output excludes --test 'Listening*' 'echo now Listening'
+./exec-assert --match 'literal,regex' --output 'contains,contains' --test '(x)#x+' --delimiter '#' 'echo "(x)"'
+./exec-assert --output contains --test 'contains the text `a.b`' "./exec-assert -v --match literal --output contains --test 'a.b' 'echo a.b'"
+if ./exec-assert --match literal --output contains --test 'a.c' 'echo abc'; then
+	exit 1
+fi
+if ./exec-assert --match 'literal,glob' --output contains --test 'a' 'echo a'; then
+	exit 1
+fi
+
+# Ordering tests
+./exec-assert --output in-order --test 'starting#migrating#ready' --delimiter '#' 'echo starting; sleep 0.1; echo migrating >&2; sleep 0.1; echo ready'
+./exec-assert --output 'excludes,stdout:in-order,stderr:empty' --test 'panic#a#b#a#' --delimiter '#' 'echo a; echo b; echo a'
+./exec-assert --result failure --output contains --test 'did not contain `ready` after `migrating` matched on line 3' "./exec-assert --output in-order --test 'starting#migrating#ready' --delimiter '#' 'echo starting; echo ready; echo migrating'"
+if ./exec-assert --output 'in-order,in-order' --test 'a#b' --delimiter '#' 'echo a; echo b'; then
+	exit 1
+fi
+
+# JSON tests
+./exec-assert --json '.items[0].status.phase == "Running"' 'echo "{\"items\": [{\"status\": {\"phase\": \"Running\"}}]}"'
+./exec-assert --json '.items | length >= 2#.kind != "Pod"' --delimiter '#' 'echo "{\"kind\": \"List\", \"items\": [1, 2]}"'
+./exec-assert --result failure --output contains --test 'found "Pending"' "./exec-assert --json '.phase == \"Running\"' 'echo {\\\"phase\\\": \\\"Pending\\\"}'"
+./exec-assert --execute until --timeout 5s --json '.count >= 2' 'echo "{\"count\": $(( $(date +%s) % 4 ))}"'
+
+# Golden file tests
+golden_dir="$( mktemp -d )"
+./exec-assert --golden "${golden_dir}/out,stderr:${golden_dir}/err" --update-golden 'echo hello; echo world >&2'
+./exec-assert --golden "${golden_dir}/out,stderr:${golden_dir}/err" 'echo hello; echo world >&2'
+EXEC_ASSERT_UPDATE=1 ./exec-assert --golden "${golden_dir}/updated" 'echo updated'
+./exec-assert --golden "${golden_dir}/updated" 'echo updated'
+./exec-assert --result failure --output contains --test '-hello
+\+goodbye' "./exec-assert --golden '${golden_dir}/out' 'echo goodbye'"
+if ./exec-assert --golden "${golden_dir}/out" 'echo hello; echo; echo'; then
+	exit 1
+fi
+./exec-assert --golden "${golden_dir}/blank" --update-golden 'echo hello; echo'
+./exec-assert --golden "${golden_dir}/blank" 'echo hello; echo'
+./exec-assert --golden "combined:${golden_dir}/combined" --update-golden 'echo hello; sleep 0.1; echo world >&2'
+./exec-assert --golden "combined:${golden_dir}/combined" 'echo hello; sleep 0.1; echo world >&2'
+rm -rf "${golden_dir}"
 
 # Exection strategy "until" tests
 ./exec-assert --execute until --output contains --test ':[0-9]5 ' --timeout 11s --interval 1s -v 'date' # so that only seconds can fulfill and re-tries happen 
@@ -57,6 +144,93 @@ fi
 if ./exec-assert --execute until --output excludes --test 'hello' --timeout 2s 'echo hello'; then
 	exit 1
 fi
+./exec-assert --result failure --match literal --output contains --test 'attempts 1-' "./exec-assert --execute until --timeout 1s --interval 200ms --output contains --test 'ready' 'exit 3'"
+./exec-assert --result failure --match literal --output contains --test ': exit 3 in ' "./exec-assert --execute until --timeout 1s --interval 200ms --output contains --test 'ready' 'exit 3'"
+./exec-assert --match literal --output contains --test 'attempts 1-3 at +' "./exec-assert -v --execute until --consecutive 3 --interval 50ms 'true'"
+flapping_attempts="$( mktemp )"
+./exec-assert --result failure --match literal --output contains --test 'the longest streak was 1 of 2 executions in a row' "./exec-assert --execute until --consecutive 2 --interval 50ms --timeout 1s 'echo >> ${flapping_attempts}; [[ \$(( \$( wc -l < ${flapping_attempts} ) % 2 )) -eq 0 ]]'"
+./exec-assert --result failure --match literal --output contains --test '"consecutive":{"required":2,"longest":1,"passed":false,' "./exec-assert --format json --execute until --consecutive 2 --interval 50ms --timeout 1s 'echo >> ${flapping_attempts}; [[ \$(( \$( wc -l < ${flapping_attempts} ) % 2 )) -eq 0 ]]'"
+rm -f "${flapping_attempts}"
+./exec-assert --result failure --output contains --test 'consecutive executions can only be required' "./exec-assert --consecutive 2 'true'"
+
+# Exection strategy "backoff" tests
+./exec-assert --match literal --output contains --test 'retrying with backoff 0.1s→0.4s x2 for 2s, or until success' "./exec-assert --execute backoff --interval 100ms --max-interval 400ms --timeout 2s 'true'"
+backoff_attempts="$( mktemp )"
+./exec-assert --match literal --output contains --test '"execute":"backoff","timeout":3,"interval":0.05,"attemptTimeout":0,"gracePeriod":5,"multiplier":2,"maxInterval":1},"duration"' "./exec-assert --format json --execute backoff --interval 50ms --max-interval 1s --timeout 3s 'echo >> ${backoff_attempts}; [[ \$( wc -l < ${backoff_attempts} ) -eq 3 ]]'"
+rm -f "${backoff_attempts}"
+./exec-assert --result failure --output contains --test 'backoff multiplier must be a finite number of at least 1' "./exec-assert --execute backoff --multiplier 0.5 'true'"
+./exec-assert --result failure --output contains --test 'backoff multiplier must be a finite number' "./exec-assert --execute backoff --multiplier NaN --timeout 2s --interval 100ms --format json 'false'"
+./exec-assert --result failure --output contains --test 'backoff jitter must be a fraction' "./exec-assert --execute backoff --jitter NaN 'false'"
+./exec-assert --result failure --output contains --test 'backoff jitter must be a fraction between 0 and 1' "./exec-assert --execute backoff --jitter 2 'true'"
+
+# Exection strategy "consistently" tests
+./exec-assert --match literal --output contains --test 'executing `true` every 0.100s for 0.500s, expecting success every time' "./exec-assert --execute consistently --interval 100ms --timeout 500ms 'true'"
+consistently_attempts="$( mktemp )"
+./exec-assert --result failure --match literal --output contains --test 'attempt 3 at +' "./exec-assert --execute consistently --interval 50ms --timeout 5s 'echo >> ${consistently_attempts}; [[ \$( wc -l < ${consistently_attempts} ) -lt 3 ]]'"
+rm -f "${consistently_attempts}"
+./exec-assert --result failure --match literal --output contains --test 'broke the assertions: the execution output assertion(s) failed' "./exec-assert --execute consistently --interval 50ms --timeout 1s --output excludes --test 'created' 'echo created'"
+
+# Deadline tests
+if ./exec-assert --timeout 1s 'sleep 10'; then
+	exit 1
+fi
+./exec-assert --result failure --output contains --test 'timed out after' "./exec-assert --timeout 1s 'sleep 10'"
+./exec-assert --result failure --output contains --test 'timed out after' "./exec-assert --result failure --timeout 1s 'trap \"exit 1\" TERM; sleep 10 & wait'" # a timeout is not a failure
+./exec-assert --result failure --output contains --test 'last execution of the command timed out' "./exec-assert --execute until --timeout 2s --attempt-timeout 500ms 'sleep 10'"
+./exec-assert --result failure --timeout 5s "./exec-assert --timeout 1s --grace-period 1s 'trap \"\" TERM; sleep 30'" # SIGKILL follows an ignored SIGTERM
+./exec-assert --match literal --output contains --test '"execute":"once","timeout":0,' "./exec-assert --format json 'true'" # once is not bound by the default timeout
+./exec-assert --timeout 500ms --interval 1s 'true' # once does not wait between executions, so the interval does not matter
+
+# Output tests
+./exec-assert --timeout 10s --output contains --test 'done' 'head -c 200000 /dev/zero | tr "\\0" x >&2; echo done' # more than a pipe buffer to stderr
+./exec-assert --output contains --test 'stdout: first
+stderr: second
+stdout: third' "./exec-assert -v 'echo first; sleep 0.1; echo second >&2; sleep 0.1; echo third'"
+./exec-assert --result failure --match literal --output contains --test 'b
+>>>error<<<: disk full
+c' "./exec-assert --output excludes --test 'error' 'echo a; echo b; echo error: disk full; echo c'"
+./exec-assert --result failure --match literal --output contains --test 'Command output to stdout:
+...
+c
+>>>error<<<
+d
+...' "./exec-assert --context 1 --output stdout:excludes --test 'error' 'echo a; echo b; echo c; echo error; echo d; echo e; echo f'"
+./exec-assert --match literal --output contains --test $'\e[1;32mSUCCESS\e[0m after' "./exec-assert --color always 'true'"
+./exec-assert --result failure --match literal --output excludes --test $'\e' "./exec-assert --output excludes --test 'x' 'echo x'" # color is only used automatically for a terminal
+./exec-assert --match literal --output excludes --test $'\e' "./exec-assert --color never 'true'"
+if ./exec-assert --color sometimes 'true'; then
+	exit 1
+fi
+
+# Report format tests
+./exec-assert --output 'contains,contains' --test '"result":{"assertion":"failure","passed":true,"reason":"the command exited with code 1"}#{"kind":"contains","target":"stderr","pattern":"c","match":"regex","passed":false,"reason":"Command output to stderr did not contain `c`"}' --match literal --delimiter '#' --result failure "./exec-assert --format json --output 'stdout:contains,stderr:contains' --test 'a#c' --delimiter '#' --result failure 'echo a; false'"
+report_dir="$( mktemp -d )"
+echo '{"tests": [{"name": "TestPasses", "command": "true"}, {"name": "TestFails", "command": "false"}, {"command": "true", "execute": "bogus"}]}' > "${report_dir}/suite.json"
+./exec-assert --output contains --test '"attempts":3,"exitCode":0,' --match literal "./exec-assert --format json --execute until --interval 100ms 'echo >> ${report_dir}/attempts; [[ \$( wc -l < ${report_dir}/attempts ) -eq 3 ]]'"
+./exec-assert --output contains --test '"stdout":"a\u001eb"' --match literal "./exec-assert --format json --execute until 'printf \"a\\x1eb\"'" # output may hold any character
+./exec-assert --name TestFirst --junit "${report_dir}/junit.xml" 'echo first'
+./exec-assert --result failure "./exec-assert --name TestSecond --junit '${report_dir}/junit.xml' --output contains --test 'missing' 'echo second >&2'"
+./exec-assert --output in-order --test '<testsuites tests="2" failures="1"#<testcase name="TestFirst" classname="exec-assert"#<system-out>first</system-out>#<testcase name="TestSecond" classname="exec-assert"#<failure message="FAILURE after#the execution output assertion(s) failed">#<system-err>second</system-err>' --match literal --delimiter '#' "cat '${report_dir}/junit.xml'"
+./exec-assert --output contains --test 'ok - executing `true` once, expecting success' --match literal "./exec-assert --format tap 'true'"
+./exec-assert --result failure --output in-order --test 'not ok - executing#  ---#  message: "the execution result assertion failed (exit code 3)"#  exitCode: 3#  stdout: "out"#  ...' --match literal --delimiter '#' "./exec-assert --format tap 'echo out; exit 3'"
+./exec-assert --result failure --output in-order --test 'TAP version 13;1..3;ok 1 - TestPasses: ;not ok 2 - TestFails: ;not ok 3 - test 3 (`true`);# FAILURE: 1 of 3 tests passed' --match literal --delimiter ';' "./exec-assert suite --format tap '${report_dir}/suite.json'"
+./exec-assert --result failure --output contains --test 'unrecognized format' "./exec-assert --format yaml 'true'"
+rm -rf "${report_dir}"
+
+# Suite tests
+./exec-assert suite test/suite.json
+./exec-assert --output contains --test 'SUCCESS: 5 of 5 tests passed' './exec-assert suite test/suite.json'
+suite_dir="$( mktemp -d )"
+echo '{"tests": [{"name": "TestPasses", "command": "true"}, {"name": "TestFails", "command": "false"}, {"command": "true", "execute": "bogus"}]}' > "${suite_dir}/suite.json"
+./exec-assert --result failure --output in-order --test 'Error running test 3 (`true`)#FAILURE: 1 of 3 tests passed; failed: TestFails, test 3 (`true`)' --match literal --delimiter '#' "./exec-assert suite '${suite_dir}/suite.json'"
+echo '{"tests": [{"command": "true", "bogus": true}]}' > "${suite_dir}/invalid.json"
+./exec-assert --result failure --output contains --test 'unknown field' "./exec-assert suite '${suite_dir}/invalid.json'"
+./exec-assert --output in-order --test '{"schemaVersion":"exec-assert/v1","kind":"test","name":"TestPasses","passed":true,#"error":"failed to configure test: unrecognized execution strategy#{"schemaVersion":"exec-assert/v1","kind":"suite","passed":false,"tests":3,"passedTests":1,"failedTests":["TestFails","test 3 (`true`)"]}' --match literal --delimiter '#' --result failure "./exec-assert suite --format json '${suite_dir}/suite.json'"
+echo '{"tests": [{"command": "true"}]}' > "${suite_dir}/first.json"
+echo '{"tests": [{"command": "echo second"}]}' > "${suite_dir}/second.json"
+./exec-assert --output in-order --test 'test 1 (`true`): executing#test 2 (`echo second`): executing' --match literal --delimiter '#' "./exec-assert suite -v --junit '${suite_dir}/junit.xml' '${suite_dir}/first.json' '${suite_dir}/second.json'"
+./exec-assert --output in-order --test '<testcase name="test 1 (`true`)"#<testcase name="test 2 (`echo second`)"' --match literal --delimiter '#' "cat '${suite_dir}/junit.xml'"
+rm -rf "${suite_dir}"
 
 # Complex command tests
 # Pipes